@@ -0,0 +1,407 @@
+//go:build darwin
+
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"macos-gh-bar/config"
+	"macos-gh-bar/core"
+	"macos-gh-bar/github"
+	"macos-gh-bar/logging"
+	"macos-gh-bar/notify"
+	"macos-gh-bar/server"
+	"macos-gh-bar/state"
+	"macos-gh-bar/view"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/progrium/darwinkit/dispatch"
+	"github.com/progrium/darwinkit/macos"
+	"github.com/progrium/darwinkit/macos/appkit"
+	"github.com/progrium/darwinkit/objc"
+)
+
+// png, 32x32
+var ghPngIcon32x32 = "iVBORw0KGgoAAAANSUhEUgAAACAAAAAgCAYAAABzenr0AAAABHNCSVQICAgIfAhkiAAAAAlwSFlzAAABAwAAAQMB4GlWSgAAABl0RVh0U29mdHdhcmUAd3d3Lmlua3NjYXBlLm9yZ5vuPBoAAAKYSURBVFiFtZdNaxNRFIafM9pQFFdK1UXQ1DRNuyz9A6mFQndFGhAUupN+/IralW66UIQuhSyqG8Gtn6C4EioVsX5EcdWFK2PbFJMeF/cOHce5M5NkeuBwmXvPed/3Zu49cyKqSloTkePAIHARKNgR4DvwzY51VW2lBlXVRAfywAqwDWiCb9vYfCrsBOIy8AhopSAOe8vmlrsSAFSBRhfEYW8A1dQCgD5gNQPisK8CfWkE1I6A3PdarABg/gjJfZ+PFACMA81Q8E17EK8BbzsgeQdcB0rAcmitCYz7vKKqiMgx4CNQ5F+bVNWn/oOIVIEKUA84mNrg+wtVXQ/kXAaehHC/YG5H29/9jGMnpTR3OeEqlxzYM6qKZxUtEW0jjvlOzIWxBOCJyCgw4Qj6lYGAhmN+QkRGPWDWEVBT1ee9sqvqM+CBY3nWwxycKFvulTxgK475QQ/zVQvbLuakZmUfMNcvbAWPw09q0L6q6kFW7KraBj67BJyJWDibFXkC5mkP00iEbUBETmXFbLEGIpbqHrDlyCtnJSAGaytOwGKGAlxYnwDmcHc0QxmU4iHcHdUcwDDQdgT8AMZ6IB+zGFHYbWA4qgl5BbwOPO8Ct4BCB8SXgNs2N7Y58ROKwB+78Bg4gSnRwZ+ujSko9wEvglSAezbmIIZYLVcx3JCsBQJeAp7jfNyN2fliArHva1EdUR7YCQTdsPMLmLL8G3gDXIgRMJKCfIfAf4YwwCSwZwN/Auc7PHQnE8j3MF1WbFc8xWFv+B64CpwDcnaUGAH9MeRNYOq/HAfQNLDvAOrvQsA+MB2ZEwNWATYyELABVJw5Ce9UgCvAZgAwFxOfC8Rt2lznK1O1bXmSiYhg6sKAqt5JiF3AHOCHmgL8L5EXS+d81uVOAAAAAElFTkSuQmCC"
+
+// summariesKept is how many refresh summaries are kept next to the logs.
+const summariesKept = 20
+
+// errorsKept is how many query errors the Errors submenu lists.
+const errorsKept = 20
+
+// minRequestedRefreshInterval is how long after a refresh started HTTP refresh requests are skipped.
+const minRequestedRefreshInterval = 10 * time.Second
+
+// offlineProbeInterval is how often GitHub is probed while offline, instead of refreshing.
+const offlineProbeInterval = 15 * time.Second
+
+type statusBar struct {
+	app          appkit.Application
+	statusItem   appkit.StatusItem
+	mainMenu     appkit.Menu
+	config       config.Configuration
+	store        *state.Store
+	tracker      *core.SnapshotTracker
+	snapshotFile string
+	logDirectory string
+	summaries    *logging.SummaryLog
+	errors       *core.ErrorHistory
+	// refreshMutex runs one refresh at a time, lastRefresh is when the last one started.
+	refreshMutex sync.Mutex
+	lastRefresh  time.Time
+	lastMutex    sync.Mutex
+	last         core.PRMenuModel
+	lastFetched  time.Time
+	offline      bool
+}
+
+func runMenuBarApp(conf config.Configuration, store *state.Store, stateDirectory string, logDirectory string) {
+	slog.Info("Connecting to GitHub API")
+	slog.Info("Booting Application")
+	macos.RunApp(func(app appkit.Application, delegate *appkit.ApplicationDelegate) {
+		slog.Info("Starting macOS Menu Bar App")
+		app.SetActivationPolicy(appkit.ApplicationActivationPolicyAccessory)
+		setupStatusBar(app, conf, store, stateDirectory, logDirectory)
+		slog.Info("Status bar set up successfully")
+	})
+}
+
+func setupStatusBar(app appkit.Application, conf config.Configuration, store *state.Store, stateDirectory string, logDirectory string) {
+	snapshotFile := filepath.Join(stateDirectory, "snapshot.json")
+	tracker := &core.SnapshotTracker{}
+	tracker.Subscribe(func(events []core.PREvent) {
+		for _, event := range events {
+			slog.Info("PR changed", "event", event.Type, "repository", event.PR.Repository, "number", event.PR.Number,
+				"previous_categories", event.PreviousCategories, "categories", event.Categories)
+		}
+	})
+	tracker.Subscribe(func(events []core.PREvent) {
+		notify.Dispatch(notify.UserNotifier{}, conf, events)
+	})
+
+	summaries, err := logging.OpenSummaryLog(logDirectory, summariesKept)
+	if err != nil {
+		slog.Warn("Error loading previous refresh summaries", "error", err)
+	}
+
+	mainMenu := view.NewMenuWithTitle("Open PRs")
+	statusItem := appkit.StatusBar_SystemStatusBar().StatusItemWithLength(appkit.VariableStatusItemLength)
+	objc.Retain(&statusItem)
+
+	img := view.AppkitImageFromBase64(ghPngIcon32x32)
+	statusItem.Button().SetImage(img)
+	statusItem.SetMenu(mainMenu)
+	statusItem.SetVisible(true)
+
+	bar := &statusBar{
+		app:          app,
+		statusItem:   statusItem,
+		mainMenu:     mainMenu,
+		config:       conf,
+		store:        store,
+		tracker:      tracker,
+		snapshotFile: snapshotFile,
+		logDirectory: logDirectory,
+		summaries:    summaries,
+		errors:       core.NewErrorHistory(errorsKept),
+	}
+	snapshot, found, err := state.LoadSnapshot(snapshotFile)
+	if err != nil {
+		slog.Warn("Error loading last PR snapshot", "path", snapshotFile, "error", err)
+	}
+	if found {
+		bar.last = core.SplitHiddenPRs(snapshot.PRs, conf, stateHideRule(store))
+		bar.lastFetched = snapshot.Time
+	}
+
+	if hotkey, _ := conf.GlobalHotkey(); hotkey != nil {
+		view.RegisterGlobalHotkey(*hotkey, func() {
+			dispatch.MainQueue().DispatchAsync(func() {
+				statusItem.Button().PerformClick(nil)
+			})
+		})
+	}
+
+	if conf.HTTPServer {
+		bar.serve(filepath.Join(stateDirectory, "server.json"))
+	}
+
+	refreshTicker := time.NewTicker(conf.GithubRefresh())
+	go func() {
+		if found {
+			bar.render(bar.last)
+		}
+		for {
+			slog.Debug("Refreshing PRs from timer")
+			bar.refresh()
+			if bar.isOffline() {
+				bar.waitUntilOnline()
+				continue
+			}
+			select {
+			case <-refreshTicker.C:
+				continue
+			}
+		}
+	}()
+
+}
+
+// refresh fetches and renders the PRs, after the refresh in progress if any.
+func (bar *statusBar) refresh() error {
+	bar.refreshMutex.Lock()
+	defer bar.refreshMutex.Unlock()
+	return bar.refreshLocked()
+}
+
+// requestRefresh refreshes for an HTTP request unless a refresh is in progress or started less than
+// minRequestedRefreshInterval ago, so scripts polling the endpoint coalesce into one fetch. The Refresh menu item calls
+// refresh instead, a click always fetches.
+func (bar *statusBar) requestRefresh() {
+	if !bar.refreshMutex.TryLock() {
+		slog.Info("Refresh already in progress, skipping requested refresh")
+		return
+	}
+	defer bar.refreshMutex.Unlock()
+	if since := time.Since(bar.lastRefresh); since < minRequestedRefreshInterval {
+		slog.Info("Refreshed recently, skipping requested refresh", "since", since)
+		return
+	}
+	slog.Info("Refreshing PRs on HTTP request")
+	bar.refreshLocked()
+}
+
+func (bar *statusBar) refreshLocked() error {
+	start := time.Now()
+	bar.lastRefresh = start
+	ghops := github.NewGithubOperations(bar.config.ResolveGithubToken()).WithRetryPolicy(bar.config.GithubRetryPolicy())
+	prsModel, errs := core.FetchPRs(ghops, bar.config, stateHideRule(bar.store))
+	err := errors.Join(errs...)
+	bar.recordSummary(start, prsModel, errs)
+	if err == nil {
+		slog.Info("Refreshed PRs", "duration", time.Since(start))
+	} else {
+		slog.Error("Error refreshing PRs", "duration", time.Since(start), "error", err)
+		bar.errors.Add(time.Now(), errs...)
+	}
+	bar.lastMutex.Lock()
+	bar.offline = core.Offline(errs)
+	if bar.offline {
+		slog.Warn("GitHub unreachable, showing the last fetched PRs", "fetched", bar.lastFetched)
+		prsModel = bar.last
+		bar.lastMutex.Unlock()
+		bar.render(prsModel)
+		return err
+	}
+	prsModel = core.CarryOverFailedCategories(prsModel, bar.last)
+	bar.last = prsModel
+	if err == nil {
+		bar.lastFetched = start
+	}
+	bar.lastMutex.Unlock()
+
+	if err == nil {
+		snapshot := state.Snapshot{Time: start, PRs: prsModel.All()}
+		if saveErr := state.SaveSnapshot(bar.snapshotFile, snapshot); saveErr != nil {
+			slog.Warn("Error saving PR snapshot", "path", bar.snapshotFile, "error", saveErr)
+		}
+	}
+	bar.tracker.Update(prsModel)
+	bar.store.Observe(prsModel.All(), time.Now())
+	if saveErr := bar.store.Save(); saveErr != nil {
+		slog.Error("Error saving PR state", "error", saveErr)
+	}
+	bar.render(prsModel)
+	return err
+}
+
+// serve exposes the PRs last rendered on localhost, for scripts and status lines.
+func (bar *statusBar) serve(infoFile string) {
+	status := func() server.Status {
+		bar.lastMutex.Lock()
+		prs, fetchedAt, offline := bar.last, bar.lastFetched, bar.offline
+		bar.lastMutex.Unlock()
+		model := view.BuildMenuModel(prs, nil, bar.config, prActionState(bar.store), time.Now())
+		return server.Status{
+			FetchedAt: fetchedAt,
+			Offline:   offline,
+			Title:     model.Title,
+			Total:     model.PRCount,
+			Unseen:    model.Unseen,
+			Overdue:   model.Overdue,
+			PRs:       prs,
+		}
+	}
+	statusServer, err := server.New(status, bar.requestRefresh)
+	if err != nil {
+		slog.Error("Error starting HTTP server", "error", err)
+		return
+	}
+	go func() {
+		if err := statusServer.ListenAndServe(bar.config.HTTPPort, infoFile); err != nil {
+			slog.Error("HTTP server stopped", "error", err)
+		}
+	}()
+}
+
+func (bar *statusBar) isOffline() bool {
+	bar.lastMutex.Lock()
+	defer bar.lastMutex.Unlock()
+	return bar.offline
+}
+
+// waitUntilOnline probes GitHub until it answers, any answer including an error response counts as online.
+func (bar *statusBar) waitUntilOnline() {
+	ghops := github.NewGithubOperations(bar.config.ResolveGithubToken())
+	probeTicker := time.NewTicker(offlineProbeInterval)
+	defer probeTicker.Stop()
+	for range probeTicker.C {
+		var networkErr *github.NetworkError
+		if err := ghops.Probe(); !errors.As(err, &networkErr) {
+			slog.Info("GitHub reachable again")
+			return
+		}
+		slog.Debug("GitHub still unreachable")
+	}
+}
+
+func (bar *statusBar) recordSummary(start time.Time, prsModel core.PRMenuModel, errs []error) {
+	summary := logging.RefreshSummary{
+		Time:       start,
+		DurationMS: time.Since(start).Milliseconds(),
+		Shown:      core.CategoryCounts(prsModel.Shown),
+		Hidden:     core.CategoryCounts(prsModel.Hidden),
+	}
+	for _, err := range errs {
+		summary.Errors = append(summary.Errors, err.Error())
+	}
+	if err := bar.summaries.Record(summary); err != nil {
+		slog.Warn("Error recording refresh summary", "error", err)
+	}
+}
+
+func (bar *statusBar) render(prs core.PRMenuModel) {
+	slog.Debug("Rendering status menu")
+	store := bar.store
+	// menu handlers run on the main queue, so re-rendering from them must not block it
+	saveAndRender := func(prs core.PRMenuModel) {
+		if err := store.Save(); err != nil {
+			slog.Error("Error saving PR state", "error", err)
+		}
+		go bar.render(prs)
+	}
+	// rehide keeps the re-split PRs as the last ones, so offline renders and the HTTP endpoint agree with the menu
+	rehide := func() {
+		bar.lastMutex.Lock()
+		rehidden := core.SplitHiddenPRs(bar.last.All(), bar.config, stateHideRule(store))
+		rehidden.Errors = bar.last.Errors
+		bar.last = rehidden
+		bar.lastMutex.Unlock()
+		saveAndRender(rehidden)
+	}
+	markSeen := func(prsToMark ...github.PullRequest) {
+		now := time.Now()
+		for _, pr := range prsToMark {
+			store.MarkSeen(pr.Repository, pr.Number, now)
+		}
+		saveAndRender(prs)
+	}
+	runAction := func(pr github.PullRequest, action view.PRAction) {
+		slog.Info("Running PR action", "action", action.Kind, "repository", pr.Repository, "number", pr.Number)
+		switch action.Kind {
+		case view.ActionOpen, view.ActionOpenFiles, view.ActionOpenChecks:
+			err := exec.Command("open", action.URL).Start()
+			view.DispatchAlertOnError(err)
+			markSeen(pr)
+		case view.ActionCopyURL, view.ActionCopyCheckout:
+			view.CopyToClipboard(action.Text)
+		case view.ActionCopyBranch:
+			if action.Text != "" {
+				view.CopyToClipboard(action.Text)
+				break
+			}
+			go func() {
+				ghops := github.NewGithubOperations(bar.config.ResolveGithubToken()).WithRetryPolicy(bar.config.GithubRetryPolicy())
+				branch, err := ghops.FetchHeadBranch(pr)
+				if view.DispatchAlertOnError(err) {
+					return
+				}
+				dispatch.MainQueue().DispatchAsync(func() {
+					view.CopyToClipboard(branch)
+				})
+			}()
+		case view.ActionMarkSeen:
+			markSeen(pr)
+		case view.ActionHidePR:
+			store.HidePR(pr.Repository, pr.Number)
+			rehide()
+		case view.ActionUnhidePR:
+			store.UnhidePR(pr.Repository, pr.Number)
+			rehide()
+		case view.ActionHideRepository:
+			store.HideRepository(pr.Repository)
+			rehide()
+		case view.ActionUnhideRepository:
+			store.UnhideRepository(pr.Repository)
+			rehide()
+		case view.ActionSnoozeHour:
+			until := time.Now().Add(time.Hour)
+			store.Snooze(pr, &until, false)
+			rehide()
+		case view.ActionSnoozeTomorrow:
+			until := tomorrowMorning(time.Now())
+			store.Snooze(pr, &until, false)
+			rehide()
+		case view.ActionSnoozeUntilPush:
+			store.Snooze(pr, nil, true)
+			rehide()
+		case view.ActionUnsnooze:
+			store.Unsnooze(pr.Repository, pr.Number)
+			rehide()
+		}
+	}
+	runCommand := func(command view.MenuCommandKind) {
+		switch command {
+		case view.CommandMarkAllSeen:
+			allShown := make([]github.PullRequest, 0)
+			for _, categoryPRs := range prs.Shown {
+				allShown = append(allShown, categoryPRs...)
+			}
+			markSeen(allShown...)
+		case view.CommandRefresh:
+			slog.Info("Refreshing PRs from button")
+			go bar.refresh()
+		case view.CommandOpenLogs:
+			err := exec.Command("open", bar.logDirectory).Start()
+			view.DispatchAlertOnError(err)
+		case view.CommandQuit:
+			bar.app.Terminate(nil)
+		}
+	}
+	openAll := func(prsToOpen []github.PullRequest) {
+		slog.Info("Opening all PRs of category", "count", len(prsToOpen))
+		for _, pr := range prsToOpen {
+			err := exec.Command("open", pr.URL).Start()
+			view.DispatchAlertOnError(err)
+		}
+		markSeen(prsToOpen...)
+	}
+	model := view.BuildMenuModel(prs, bar.errors.Entries(), bar.config, prActionState(store), time.Now())
+	bar.lastMutex.Lock()
+	if bar.offline {
+		model.Notice = view.OfflineNotice(bar.lastFetched)
+	}
+	bar.lastMutex.Unlock()
+	slog.Info("Rendering status menu", "count", model.PRCount, "unseen", model.Unseen)
+	dispatch.MainQueue().DispatchSync(func() {
+		view.RenderStatusMenu(bar.statusItem, bar.mainMenu, model, view.MenuHandlers{
+			RunPRAction: runAction,
+			RunCommand:  runCommand,
+			OpenAll:     openAll,
+		})
+	})
+}
@@ -0,0 +1,15 @@
+//go:build !darwin
+
+package main
+
+import (
+	"fmt"
+	"macos-gh-bar/config"
+	"macos-gh-bar/state"
+	"os"
+)
+
+func runMenuBarApp(conf config.Configuration, store *state.Store, stateDirectory string, logDirectory string) {
+	fmt.Fprintln(os.Stderr, "the menu bar app requires macOS, use the list command to print the PRs instead")
+	os.Exit(1)
+}
@@ -0,0 +1,482 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"macos-gh-bar/github"
+	"macos-gh-bar/slices"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+func LoadConfiguration(configFile string) (Configuration, error) {
+	configFileRaw, err := os.ReadFile(configFile)
+	if err != nil {
+		return Configuration{}, fmt.Errorf("error while reading configuration file %s: %w", configFile, err)
+	}
+	conf := Configuration{}
+	err = yaml.Unmarshal(configFileRaw, &conf)
+	if err != nil {
+		return Configuration{}, fmt.Errorf("error while parsing configuration file %s: %w", configFile, err)
+	}
+
+	if _, err := conf.SlogLevel(); err != nil {
+		return Configuration{}, fmt.Errorf("error while parsing configuration file %s: %w", configFile, err)
+	}
+	for category, group := range conf.QueryGroups {
+		if _, err := group.SortKeys(); err != nil {
+			return Configuration{}, fmt.Errorf("error while parsing configuration file %s, query group %s: %w", configFile, category, err)
+		}
+		if _, err := group.Grouping(); err != nil {
+			return Configuration{}, fmt.Errorf("error while parsing configuration file %s, query group %s: %w", configFile, category, err)
+		}
+		if _, err := group.Badge(); err != nil {
+			return Configuration{}, fmt.Errorf("error while parsing configuration file %s, query group %s: %w", configFile, category, err)
+		}
+		if _, err := group.ItemTemplate(); err != nil {
+			return Configuration{}, fmt.Errorf("error while parsing configuration file %s, query group %s: %w", configFile, category, err)
+		}
+	}
+	if _, err := conf.WorkingWeekdays(); err != nil {
+		return Configuration{}, fmt.Errorf("error while parsing configuration file %s: %w", configFile, err)
+	}
+	if _, err := conf.HolidayDates(); err != nil {
+		return Configuration{}, fmt.Errorf("error while parsing configuration file %s: %w", configFile, err)
+	}
+	if _, err := conf.StatusTitleTemplate(); err != nil {
+		return Configuration{}, fmt.Errorf("error while parsing configuration file %s: %w", configFile, err)
+	}
+	if _, err := conf.GlobalHotkey(); err != nil {
+		return Configuration{}, fmt.Errorf("error while parsing configuration file %s: %w", configFile, err)
+	}
+	return conf, nil
+}
+
+type PRFilter struct {
+	Category   *string `yaml:"category,omitempty"`
+	Repository *string `yaml:"repository,omitempty"`
+	Title      *string `yaml:"title,omitempty"`
+	Author     *string `yaml:"author,omitempty"`
+	Draft      *bool   `yaml:"draft,omitempty"`
+}
+
+func (filter PRFilter) Match(pr github.PullRequest) bool {
+	components := make([]bool, 0)
+	anyMatch := false
+	if filter.Repository != nil {
+		anyMatch = true
+		components = append(components, regexp.MustCompile(*filter.Repository).MatchString(pr.Repository))
+	}
+	if filter.Title != nil {
+		anyMatch = true
+		components = append(components, regexp.MustCompile(*filter.Title).MatchString(pr.Title))
+	}
+	if filter.Author != nil {
+		anyMatch = true
+		components = append(components, regexp.MustCompile(*filter.Author).MatchString(pr.Author))
+	}
+	if filter.Draft != nil {
+		anyMatch = true
+		components = append(components, pr.Draft == *filter.Draft)
+	}
+	result := anyMatch
+	for _, condition := range components {
+		result = result && condition
+	}
+	return result
+}
+
+func (filter PRFilter) MatchWithCategory(pr github.PullRequest, category string) bool {
+	components := make([]bool, 0)
+	if filter.Category != nil {
+		components = append(components, regexp.MustCompile(*filter.Category).MatchString(category))
+	}
+	components = append(components, filter.Match(pr))
+
+	result := true
+	for _, condition := range components {
+		result = result && condition
+	}
+	return result
+}
+
+type QueryGroup struct {
+	Queries []string   `yaml:"queries,omitempty"`
+	Repos   []string   `yaml:"repos,omitempty"`
+	Filters []PRFilter `yaml:"filters,omitempty"`
+	// Sort lists sort keys, each a field optionally followed by asc or desc, e.g. "updated desc".
+	Sort    []string `yaml:"sort,omitempty"`
+	Flatten bool     `yaml:"flatten,omitempty"`
+	GroupBy string   `yaml:"group_by,omitempty"`
+	SLA     *SLA     `yaml:"sla,omitempty"`
+	// CountInBadge defaults to true, the status bar title only counts PRs of contributing categories.
+	CountInBadge *bool  `yaml:"count_in_badge,omitempty"`
+	BadgeStyle   string `yaml:"badge_style,omitempty"`
+	// ItemFormat is a template of the PR entries, ItemMaxWidth truncates them to that many characters.
+	ItemFormat   string `yaml:"item_format,omitempty"`
+	ItemMaxWidth int    `yaml:"item_max_width,omitempty"`
+	// Order places the category in the menu, lower first, then alphabetically.
+	Order int `yaml:"order,omitempty"`
+}
+
+// ItemTemplate parses item_format, nil when unset.
+func (g QueryGroup) ItemTemplate() (*template.Template, error) {
+	if g.ItemFormat == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("item_format").Parse(g.ItemFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid item_format: %w", err)
+	}
+	return tmpl, nil
+}
+
+const (
+	BadgeStyleCount = "count"
+	BadgeStyleDot   = "dot"
+	BadgeStyleNone  = "none"
+)
+
+func (g QueryGroup) CountsInBadge() bool {
+	return g.CountInBadge == nil || *g.CountInBadge
+}
+
+// Badge is the badge_style of the category header, defaulting to count.
+func (g QueryGroup) Badge() (string, error) {
+	switch g.BadgeStyle {
+	case "":
+		return BadgeStyleCount, nil
+	case BadgeStyleCount, BadgeStyleDot, BadgeStyleNone:
+		return g.BadgeStyle, nil
+	}
+	return BadgeStyleCount, fmt.Errorf("unknown badge_style %s, expected count, dot or none", g.BadgeStyle)
+}
+
+// SLA marks PRs as aging then overdue once they waited the given business hours, counted from the latest review
+// request when use_review_requested is set, otherwise from the last update.
+type SLA struct {
+	AgingAfterHours    int  `yaml:"aging_after_hours"`
+	OverdueAfterHours  int  `yaml:"overdue_after_hours"`
+	UseReviewRequested bool `yaml:"use_review_requested"`
+}
+
+const (
+	GroupByRepository     = "repository"
+	GroupByAuthor         = "author"
+	GroupByLabel          = "label"
+	GroupByBaseBranch     = "base_branch"
+	GroupByReviewDecision = "review_decision"
+	GroupByAge            = "age"
+	GroupByNone           = "none"
+)
+
+// Grouping is the group_by of the group, defaulting to repository, or none when flattened.
+func (g QueryGroup) Grouping() (string, error) {
+	switch g.GroupBy {
+	case "":
+		if g.Flatten {
+			return GroupByNone, nil
+		}
+		return GroupByRepository, nil
+	case GroupByRepository, GroupByAuthor, GroupByLabel, GroupByBaseBranch, GroupByReviewDecision, GroupByAge, GroupByNone:
+		return g.GroupBy, nil
+	}
+	return GroupByRepository, fmt.Errorf("unknown group_by %s, expected repository, author, label, base_branch, review_decision, age or none", g.GroupBy)
+}
+
+const (
+	SortUpdated     = "updated"
+	SortCreated     = "created"
+	SortNumber      = "number"
+	SortTitle       = "title"
+	SortAuthor      = "author"
+	SortReviewState = "review_state"
+	SortCIState     = "ci_state"
+)
+
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// ParseSortKey reads a field optionally followed by asc or desc. Updated defaults to most recent first, every other
+// field to ascending.
+func ParseSortKey(raw string) (SortKey, error) {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 || len(fields) > 2 {
+		return SortKey{}, fmt.Errorf("invalid sort key %q, expected a field optionally followed by asc or desc", raw)
+	}
+	key := SortKey{Field: fields[0], Descending: fields[0] == SortUpdated}
+	switch key.Field {
+	case SortUpdated, SortCreated, SortNumber, SortTitle, SortAuthor, SortReviewState, SortCIState:
+	default:
+		return SortKey{}, fmt.Errorf("unknown sort field %s in %q", key.Field, raw)
+	}
+	if len(fields) == 2 {
+		switch fields[1] {
+		case "asc":
+			key.Descending = false
+		case "desc":
+			key.Descending = true
+		default:
+			return SortKey{}, fmt.Errorf("invalid sort direction %s in %q, expected asc or desc", fields[1], raw)
+		}
+	}
+	return key, nil
+}
+
+func (g QueryGroup) SortKeys() ([]SortKey, error) {
+	keys := make([]SortKey, 0, len(g.Sort))
+	for _, raw := range g.Sort {
+		key, err := ParseSortKey(raw)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// UnmarshalYAML accepts either a plain list of search queries or a mapping with queries, repos and filters.
+func (g *QueryGroup) UnmarshalYAML(unmarshal func(any) error) error {
+	var queries []string
+	if err := unmarshal(&queries); err == nil {
+		*g = QueryGroup{Queries: queries}
+		return nil
+	}
+	type plainQueryGroup QueryGroup
+	return unmarshal((*plainQueryGroup)(g))
+}
+
+func (g QueryGroup) MatchFilters(pr github.PullRequest) bool {
+	if len(g.Filters) == 0 {
+		return true
+	}
+	return slices.Any(g.Filters, func(filter PRFilter) bool {
+		return filter.Match(pr)
+	})
+}
+
+type Configuration struct {
+	GithubToken            string                `yaml:"github_token"`
+	GithubRefreshInterval  int                   `yaml:"github_refresh_interval"`
+	ShowDrafts             bool                  `yaml:"show_drafts"`
+	IgnorePRs              []PRFilter            `yaml:"ignore_prs"`
+	QueryGroups            map[string]QueryGroup `yaml:"query_groups"`
+	HidePRs                []PRFilter            `yaml:"hide_prs"`
+	RenderHiddenPRs        bool                  `yaml:"render_hidden_prs"`
+	FetchPRStatus          bool                  `yaml:"fetch_pr_status"`
+	Notifications          map[string][]string   `yaml:"notifications"`
+	LogLevel               string                `yaml:"log_level"`
+	LogMaxSizeMB           int                   `yaml:"log_max_size_mb"`
+	LogMaxFiles            int                   `yaml:"log_max_files"`
+	RetryMaxAttempts       int                   `yaml:"retry_max_attempts"`
+	RetryBaseDelayMS       int                   `yaml:"retry_base_delay_ms"`
+	RetryMaxDelayMS        int                   `yaml:"retry_max_delay_ms"`
+	RetryMaxRateLimitWaitS int                   `yaml:"retry_max_rate_limit_wait_s"`
+	WorkingDays            []string              `yaml:"working_days"`
+	Holidays               []string              `yaml:"holidays"`
+	TitleTemplate          string                `yaml:"title_template"`
+	GlobalHotkeyKeys       string                `yaml:"global_hotkey"`
+	HTTPServer             bool                  `yaml:"http_server"`
+	HTTPPort               int                   `yaml:"http_port"`
+	TitleIconOnly          bool                  `yaml:"title_icon_only"`
+}
+
+func (c Configuration) MatchIgnoredPRs(pr github.PullRequest) bool {
+	return slices.Any(c.IgnorePRs, func(filter PRFilter) bool {
+		return filter.Match(pr)
+	})
+}
+
+func (c Configuration) GithubRefresh() time.Duration {
+	return time.Duration(c.GithubRefreshInterval) * time.Second
+}
+
+func (c Configuration) ResolveGithubToken() string {
+	if c.GithubToken == "" {
+		return os.Getenv("GH_TOKEN")
+	}
+	return c.GithubToken
+}
+
+func (c Configuration) MatchHidePRs(pr github.PullRequest, category string) bool {
+	return slices.Any(c.HidePRs, func(filter PRFilter) bool {
+		return filter.MatchWithCategory(pr, category)
+	})
+}
+
+func (c Configuration) NotificationEnabled(category string, kind string) bool {
+	for _, enabled := range c.Notifications[category] {
+		if enabled == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// SlogLevel parses log_level, one of debug, info, warn or error, defaulting to info.
+func (c Configuration) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	if c.LogLevel == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("invalid log_level %s: %w", c.LogLevel, err)
+	}
+	return level, nil
+}
+
+func (c Configuration) LogMaxSize() int64 {
+	if c.LogMaxSizeMB <= 0 {
+		return 5 * 1024 * 1024
+	}
+	return int64(c.LogMaxSizeMB) * 1024 * 1024
+}
+
+// LogMaxBackups is how many rotated log files are kept besides the current one.
+func (c Configuration) LogMaxBackups() int {
+	if c.LogMaxFiles <= 0 {
+		return 3
+	}
+	return c.LogMaxFiles - 1
+}
+
+// GithubRetryPolicy defaults to 3 attempts, backing off from 500ms up to 10s, and waiting up to a minute for a rate
+// limit to lift.
+func (c Configuration) GithubRetryPolicy() github.RetryPolicy {
+	policy := github.RetryPolicy{
+		MaxAttempts:      c.RetryMaxAttempts,
+		BaseDelay:        time.Duration(c.RetryBaseDelayMS) * time.Millisecond,
+		MaxDelay:         time.Duration(c.RetryMaxDelayMS) * time.Millisecond,
+		MaxRateLimitWait: time.Duration(c.RetryMaxRateLimitWaitS) * time.Second,
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = 500 * time.Millisecond
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 10 * time.Second
+	}
+	if policy.MaxRateLimitWait <= 0 {
+		policy.MaxRateLimitWait = time.Minute
+	}
+	return policy
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// WorkingWeekdays parses working_days, three letter day names defaulting to Monday to Friday.
+func (c Configuration) WorkingWeekdays() (map[time.Weekday]bool, error) {
+	days := c.WorkingDays
+	if len(days) == 0 {
+		days = []string{"mon", "tue", "wed", "thu", "fri"}
+	}
+	working := make(map[time.Weekday]bool, len(days))
+	for _, day := range days {
+		weekday, found := weekdays[strings.ToLower(day)]
+		if !found {
+			return nil, fmt.Errorf("invalid working day %s, expected one of mon, tue, wed, thu, fri, sat or sun", day)
+		}
+		working[weekday] = true
+	}
+	return working, nil
+}
+
+// HolidayDates parses holidays, dates formatted as 2006-01-02, keyed by that same format.
+func (c Configuration) HolidayDates() (map[string]bool, error) {
+	holidays := make(map[string]bool, len(c.Holidays))
+	for _, holiday := range c.Holidays {
+		date, err := time.Parse(time.DateOnly, holiday)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday %s, expected YYYY-MM-DD: %w", holiday, err)
+		}
+		holidays[date.Format(time.DateOnly)] = true
+	}
+	return holidays, nil
+}
+
+// titleFields mirrors the fields and methods view.TitleData offers to title_template, which config cannot import.
+type titleFields struct {
+	Total     int
+	Unseen    int
+	Overdue   int
+	ToReview  int
+	Mine      int
+	FailingCI int
+}
+
+func (titleFields) Category(name string) int {
+	return 0
+}
+
+// StatusTitleTemplate parses title_template and checks it only uses the title fields, nil when unset.
+func (c Configuration) StatusTitleTemplate() (*template.Template, error) {
+	if c.TitleTemplate == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("title_template").Parse(c.TitleTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid title_template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, titleFields{}); err != nil {
+		return nil, fmt.Errorf("invalid title_template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Hotkey is a key combined with modifiers, like cmd+shift+p.
+type Hotkey struct {
+	Command bool
+	Shift   bool
+	Option  bool
+	Control bool
+	Key     string
+}
+
+// GlobalHotkey parses global_hotkey, modifiers among cmd, shift, opt and ctrl joined by + before a single key, nil
+// when unset.
+func (c Configuration) GlobalHotkey() (*Hotkey, error) {
+	if c.GlobalHotkeyKeys == "" {
+		return nil, nil
+	}
+	parts := strings.Split(strings.ToLower(c.GlobalHotkeyKeys), "+")
+	hotkey := &Hotkey{Key: parts[len(parts)-1]}
+	for _, modifier := range parts[:len(parts)-1] {
+		switch modifier {
+		case "cmd", "command":
+			hotkey.Command = true
+		case "shift":
+			hotkey.Shift = true
+		case "opt", "option", "alt":
+			hotkey.Option = true
+		case "ctrl", "control":
+			hotkey.Control = true
+		default:
+			return nil, fmt.Errorf("invalid global_hotkey %s, unknown modifier %s", c.GlobalHotkeyKeys, modifier)
+		}
+	}
+	if len([]rune(hotkey.Key)) != 1 {
+		return nil, fmt.Errorf("invalid global_hotkey %s, expected a single key after the modifiers", c.GlobalHotkeyKeys)
+	}
+	if !hotkey.Command && !hotkey.Option && !hotkey.Control {
+		return nil, fmt.Errorf("invalid global_hotkey %s, expected at least one of cmd, opt or ctrl", c.GlobalHotkeyKeys)
+	}
+	return hotkey, nil
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"macos-gh-bar/config"
+	"macos-gh-bar/github"
+	"strconv"
+)
+
+type BadgeCounts struct {
+	Total  int
+	Unseen int
+	// Dot tells a contributing category with the dot badge_style has PRs.
+	Dot bool
+}
+
+// CountBadge counts the distinct PRs of the contributing categories with the count badge_style, so a PR listed in
+// several categories is counted once. Categories with the dot style only tell whether they have PRs, those with none
+// do not contribute.
+func CountBadge(categoryPRs map[string][]github.PullRequest, groups map[string]config.QueryGroup, seen func(pr github.PullRequest) bool) BadgeCounts {
+	counted := make(map[string]bool)
+	var counts BadgeCounts
+	for category, prs := range categoryPRs {
+		group := groups[category]
+		if !group.CountsInBadge() {
+			continue
+		}
+		switch style, _ := group.Badge(); style {
+		case config.BadgeStyleNone:
+			continue
+		case config.BadgeStyleDot:
+			counts.Dot = counts.Dot || len(prs) > 0
+			continue
+		}
+		for _, pr := range prs {
+			key := pr.Repository + "#" + strconv.Itoa(pr.Number)
+			if counted[key] {
+				continue
+			}
+			counted[key] = true
+			counts.Total++
+			if !seen(pr) {
+				counts.Unseen++
+			}
+		}
+	}
+	return counts
+}
+
+// CategoryBadge is the header badge of a category with the given PR count in the given badge_style.
+func CategoryBadge(count int, style string) string {
+	switch style {
+	case config.BadgeStyleNone:
+		return ""
+	case config.BadgeStyleDot:
+		if count == 0 {
+			return ""
+		}
+		return "●"
+	}
+	return strconv.Itoa(count)
+}
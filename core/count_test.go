@@ -0,0 +1,83 @@
+package core
+
+import (
+	"macos-gh-bar/config"
+	"macos-gh-bar/github"
+	"testing"
+)
+
+func TestCountBadge(t *testing.T) {
+	notCounted := false
+	seen := func(pr github.PullRequest) bool {
+		return pr.Number == 1
+	}
+	categoryPRs := map[string][]github.PullRequest{
+		"To Review": {pr("acme/api", 1), pr("acme/api", 2)},
+		"Created":   {pr("acme/web", 3)},
+		"Assigned":  {pr("acme/api", 2), pr("acme/web", 4)},
+		"Empty":     {},
+	}
+
+	tests := []struct {
+		name   string
+		groups map[string]config.QueryGroup
+		want   BadgeCounts
+	}{
+		{
+			name: "PRs in several categories are counted once",
+			want: BadgeCounts{Total: 4, Unseen: 3},
+		},
+		{
+			name:   "count_in_badge false",
+			groups: map[string]config.QueryGroup{"Created": {CountInBadge: &notCounted}},
+			want:   BadgeCounts{Total: 3, Unseen: 2},
+		},
+		{
+			name:   "none style",
+			groups: map[string]config.QueryGroup{"Assigned": {BadgeStyle: config.BadgeStyleNone}},
+			want:   BadgeCounts{Total: 3, Unseen: 2},
+		},
+		{
+			name:   "dot style",
+			groups: map[string]config.QueryGroup{"Assigned": {BadgeStyle: config.BadgeStyleDot}},
+			want:   BadgeCounts{Total: 3, Unseen: 2, Dot: true},
+		},
+		{
+			name:   "dot style without PRs",
+			groups: map[string]config.QueryGroup{"Empty": {BadgeStyle: config.BadgeStyleDot}, "Assigned": {BadgeStyle: config.BadgeStyleNone}},
+			want:   BadgeCounts{Total: 3, Unseen: 2},
+		},
+		{
+			name:   "dot style not counting in badge",
+			groups: map[string]config.QueryGroup{"Assigned": {BadgeStyle: config.BadgeStyleDot, CountInBadge: &notCounted}},
+			want:   BadgeCounts{Total: 3, Unseen: 2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CountBadge(categoryPRs, test.groups, seen); got != test.want {
+				t.Errorf("CountBadge() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCategoryBadge(t *testing.T) {
+	tests := []struct {
+		count int
+		style string
+		want  string
+	}{
+		{count: 3, style: config.BadgeStyleCount, want: "3"},
+		{count: 0, style: config.BadgeStyleCount, want: "0"},
+		{count: 3, style: "", want: "3"},
+		{count: 3, style: config.BadgeStyleDot, want: "●"},
+		{count: 0, style: config.BadgeStyleDot, want: ""},
+		{count: 3, style: config.BadgeStyleNone, want: ""},
+	}
+	for _, test := range tests {
+		if got := CategoryBadge(test.count, test.style); got != test.want {
+			t.Errorf("CategoryBadge(%d, %q) = %q, want %q", test.count, test.style, got, test.want)
+		}
+	}
+}
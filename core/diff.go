@@ -0,0 +1,201 @@
+package core
+
+import (
+	"macos-gh-bar/github"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type PREventType string
+
+const (
+	PRAdded         PREventType = "added"
+	PRRemoved       PREventType = "removed"
+	PRMovedCategory PREventType = "moved_category"
+	PRStatusChanged PREventType = "status_changed"
+)
+
+type PREvent struct {
+	Type PREventType
+	PR   github.PullRequest
+	// Previous is the PR as it was in the older snapshot, zero for PRAdded.
+	Previous           github.PullRequest
+	Categories         []string
+	PreviousCategories []string
+}
+
+// AddedCategories lists the categories the PR entered with this event.
+func (event PREvent) AddedCategories() []string {
+	return categoriesDifference(event.Categories, event.PreviousCategories)
+}
+
+func (event PREvent) RemovedCategories() []string {
+	return categoriesDifference(event.PreviousCategories, event.Categories)
+}
+
+type prSnapshot struct {
+	pr github.PullRequest
+	// categories are those showing the PR, empty when it is hidden or snoozed in all of them.
+	categories []string
+	shown      bool
+}
+
+// DiffModels compares the PRs of two snapshots, keyed by repository and number, and lists what changed between them.
+// A PR that both moved category and changed status yields one event of each type. Only shown PRs yield events, and
+// hiding, snoozing or unhiding a PR is not a change, so the actions of the user are not notified back. Categories
+// are those showing the PR.
+func DiffModels(previous, next PRMenuModel) []PREvent {
+	previousSnapshots, previousKeys := indexPRs(previous)
+	nextSnapshots, nextKeys := indexPRs(next)
+	events := make([]PREvent, 0)
+	for _, key := range nextKeys {
+		current := nextSnapshots[key]
+		if !current.shown {
+			continue
+		}
+		old, found := previousSnapshots[key]
+		if !found {
+			events = append(events, PREvent{Type: PRAdded, PR: current.pr, Categories: current.categories})
+			continue
+		}
+		if old.shown && !equalCategories(old.categories, current.categories) {
+			events = append(events, PREvent{
+				Type: PRMovedCategory, PR: current.pr, Previous: old.pr,
+				Categories: current.categories, PreviousCategories: old.categories,
+			})
+		}
+		if statusChanged(old.pr, current.pr) {
+			events = append(events, PREvent{
+				Type: PRStatusChanged, PR: current.pr, Previous: old.pr,
+				Categories: current.categories, PreviousCategories: old.categories,
+			})
+		}
+	}
+	for _, key := range previousKeys {
+		if _, found := nextSnapshots[key]; !found && previousSnapshots[key].shown {
+			old := previousSnapshots[key]
+			events = append(events, PREvent{Type: PRRemoved, PR: old.pr, Previous: old.pr, PreviousCategories: old.categories})
+		}
+	}
+	return events
+}
+
+// statusChanged ignores unknown states so turning status fetching on or a failed status call doesn't report a change.
+func statusChanged(old, current github.PullRequest) bool {
+	if old.HeadSHA != "" && current.HeadSHA != "" && old.HeadSHA != current.HeadSHA {
+		return true
+	}
+	if old.ReviewState != github.ReviewStateUnknown && current.ReviewState != github.ReviewStateUnknown && old.ReviewState != current.ReviewState {
+		return true
+	}
+	if old.CIState != github.CIStateUnknown && current.CIState != github.CIStateUnknown && old.CIState != current.CIState {
+		return true
+	}
+	return false
+}
+
+func indexPRs(model PRMenuModel) (map[string]prSnapshot, []string) {
+	snapshots := make(map[string]prSnapshot)
+	index := func(categoryPRs map[string][]github.PullRequest, shown bool) {
+		for category, prs := range categoryPRs {
+			for _, pr := range prs {
+				key := pr.Repository + "#" + strconv.Itoa(pr.Number)
+				snapshot, found := snapshots[key]
+				if !found || shown && !snapshot.shown {
+					snapshot.pr = pr
+				}
+				snapshot.shown = snapshot.shown || shown
+				if shown && !containsCategory(snapshot.categories, category) {
+					snapshot.categories = append(snapshot.categories, category)
+				}
+				snapshots[key] = snapshot
+			}
+		}
+	}
+	index(model.Shown, true)
+	index(model.Hidden, false)
+	keys := make([]string, 0, len(snapshots))
+	for key, snapshot := range snapshots {
+		sort.Strings(snapshot.categories)
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return snapshots, keys
+}
+
+func containsCategory(categories []string, category string) bool {
+	for _, c := range categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+func equalCategories(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func categoriesDifference(categories, others []string) []string {
+	difference := make([]string, 0)
+	for _, category := range categories {
+		if !containsCategory(others, category) {
+			difference = append(difference, category)
+		}
+	}
+	return difference
+}
+
+// EventPublisher fans out the events of each refresh to every subscriber, in subscription order.
+type EventPublisher struct {
+	mutex       sync.Mutex
+	subscribers []func([]PREvent)
+}
+
+func (p *EventPublisher) Subscribe(subscriber func([]PREvent)) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.subscribers = append(p.subscribers, subscriber)
+}
+
+func (p *EventPublisher) Publish(events []PREvent) {
+	p.mutex.Lock()
+	subscribers := append([]func([]PREvent){}, p.subscribers...)
+	p.mutex.Unlock()
+	for _, subscriber := range subscribers {
+		subscriber(events)
+	}
+}
+
+// SnapshotTracker keeps the last fetched model and publishes how each new one differs from it.
+type SnapshotTracker struct {
+	EventPublisher
+	mutex    sync.Mutex
+	previous *PRMenuModel
+}
+
+// Update publishes the events between the previous and the given model. The first model only becomes the baseline,
+// otherwise every PR would be reported as added on startup.
+func (t *SnapshotTracker) Update(model PRMenuModel) []PREvent {
+	t.mutex.Lock()
+	previous := t.previous
+	t.previous = &model
+	t.mutex.Unlock()
+	if previous == nil {
+		return nil
+	}
+	events := DiffModels(*previous, model)
+	if len(events) > 0 {
+		t.Publish(events)
+	}
+	return events
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"fmt"
+	"macos-gh-bar/github"
+	"reflect"
+	"testing"
+)
+
+func pr(repository string, number int) github.PullRequest {
+	return github.PullRequest{Repository: repository, Number: number, Title: fmt.Sprintf("PR %d", number)}
+}
+
+func shown(categoryPRs map[string][]github.PullRequest) PRMenuModel {
+	return PRMenuModel{Shown: categoryPRs}
+}
+
+func hidden(categoryPRs map[string][]github.PullRequest) PRMenuModel {
+	return PRMenuModel{Hidden: categoryPRs}
+}
+
+func describeEvents(events []PREvent) []string {
+	described := make([]string, 0, len(events))
+	for _, event := range events {
+		described = append(described, fmt.Sprintf("%s %s#%d %v->%v", event.Type, event.PR.Repository, event.PR.Number, event.PreviousCategories, event.Categories))
+	}
+	return described
+}
+
+func TestDiffModels(t *testing.T) {
+	approved := pr("acme/api", 1)
+	approved.ReviewState = github.ReviewStateApproved
+	pending := pr("acme/api", 1)
+	pending.ReviewState = github.ReviewStatePending
+	unknown := pr("acme/api", 1)
+
+	tests := []struct {
+		name     string
+		previous PRMenuModel
+		next     PRMenuModel
+		want     []string
+	}{
+		{
+			name:     "added",
+			previous: shown(map[string][]github.PullRequest{"To Review": {}}),
+			next:     shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			want:     []string{"added acme/api#1 []->[To Review]"},
+		},
+		{
+			name:     "removed",
+			previous: shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			next:     shown(map[string][]github.PullRequest{"To Review": {}}),
+			want:     []string{"removed acme/api#1 [To Review]->[]"},
+		},
+		{
+			name:     "moved between categories",
+			previous: shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			next:     shown(map[string][]github.PullRequest{"Approved": {pr("acme/api", 1)}}),
+			want:     []string{"moved_category acme/api#1 [To Review]->[Approved]"},
+		},
+		{
+			name:     "listed in two categories",
+			previous: shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			next:     shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}, "Created": {pr("acme/api", 1)}}),
+			want:     []string{"moved_category acme/api#1 [To Review]->[Created To Review]"},
+		},
+		{
+			name:     "unchanged in two categories",
+			previous: shown(map[string][]github.PullRequest{"Created": {pr("acme/api", 1)}, "To Review": {pr("acme/api", 1)}}),
+			next:     shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}, "Created": {pr("acme/api", 1)}}),
+			want:     []string{},
+		},
+		{
+			name:     "status changed",
+			previous: shown(map[string][]github.PullRequest{"Created": {pending}}),
+			next:     shown(map[string][]github.PullRequest{"Created": {approved}}),
+			want:     []string{"status_changed acme/api#1 [Created]->[Created]"},
+		},
+		{
+			name:     "unknown to known state",
+			previous: shown(map[string][]github.PullRequest{"Created": {unknown}}),
+			next:     shown(map[string][]github.PullRequest{"Created": {approved}}),
+			want:     []string{},
+		},
+		{
+			name:     "moved and status changed",
+			previous: shown(map[string][]github.PullRequest{"To Review": {pending}}),
+			next:     shown(map[string][]github.PullRequest{"Approved": {approved}}),
+			want: []string{
+				"moved_category acme/api#1 [To Review]->[Approved]",
+				"status_changed acme/api#1 [To Review]->[Approved]",
+			},
+		},
+		{
+			name:     "hidden PRs are ignored",
+			previous: shown(map[string][]github.PullRequest{"To Review": {}}),
+			next:     hidden(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			want:     []string{},
+		},
+		{
+			name:     "shown PR snoozed",
+			previous: shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			next:     hidden(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			want:     []string{},
+		},
+		{
+			name:     "snoozed PR shown again",
+			previous: hidden(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			next:     shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			want:     []string{},
+		},
+		{
+			name:     "snoozed PR moved while snoozed",
+			previous: hidden(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			next:     shown(map[string][]github.PullRequest{"Approved": {approved}}),
+			want:     []string{},
+		},
+		{
+			name:     "snoozed PR status changed while snoozed",
+			previous: hidden(map[string][]github.PullRequest{"Created": {pending}}),
+			next:     shown(map[string][]github.PullRequest{"Created": {approved}}),
+			want:     []string{"status_changed acme/api#1 []->[Created]"},
+		},
+		{
+			name:     "hidden PR removed",
+			previous: hidden(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			next:     shown(map[string][]github.PullRequest{"To Review": {}}),
+			want:     []string{},
+		},
+		{
+			name:     "shown in one category and hidden in another",
+			previous: PRMenuModel{Shown: map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}, Hidden: map[string][]github.PullRequest{"Created": {pr("acme/api", 1)}}},
+			next:     shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}}),
+			want:     []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := describeEvents(DiffModels(test.previous, test.next))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("DiffModels() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPREventCategories(t *testing.T) {
+	event := PREvent{Categories: []string{"Approved", "Created"}, PreviousCategories: []string{"Created", "To Review"}}
+	if got := event.AddedCategories(); !reflect.DeepEqual(got, []string{"Approved"}) {
+		t.Errorf("AddedCategories() = %q", got)
+	}
+	if got := event.RemovedCategories(); !reflect.DeepEqual(got, []string{"To Review"}) {
+		t.Errorf("RemovedCategories() = %q", got)
+	}
+}
+
+func TestSnapshotTrackerFirstUpdateIsBaseline(t *testing.T) {
+	tracker := &SnapshotTracker{}
+	var published [][]PREvent
+	tracker.Subscribe(func(events []PREvent) {
+		published = append(published, events)
+	})
+
+	if events := tracker.Update(shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1)}})); events != nil {
+		t.Fatalf("first Update() = %q, want no events", describeEvents(events))
+	}
+	if len(published) != 0 {
+		t.Fatalf("first Update() published %d times", len(published))
+	}
+
+	events := tracker.Update(shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1), pr("acme/web", 2)}}))
+	want := []string{"added acme/web#2 []->[To Review]"}
+	if got := describeEvents(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("second Update() = %q, want %q", got, want)
+	}
+	if len(published) != 1 || !reflect.DeepEqual(describeEvents(published[0]), want) {
+		t.Errorf("published %v, want one batch %q", published, want)
+	}
+
+	tracker.Update(shown(map[string][]github.PullRequest{"To Review": {pr("acme/api", 1), pr("acme/web", 2)}}))
+	if len(published) != 1 {
+		t.Errorf("unchanged Update() published, got %d batches", len(published))
+	}
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"errors"
+	"macos-gh-bar/github"
+	"macos-gh-bar/slices"
+	"sync"
+	"time"
+)
+
+// QueryError is the failure of a single query of a category, a search query or a repo:owner/name listing.
+type QueryError struct {
+	Category string
+	Query    string
+	Err      error
+}
+
+func (e QueryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e QueryError) Unwrap() error {
+	return e.Err
+}
+
+// FailedCategories lists the categories with at least one failed query.
+func (model PRMenuModel) FailedCategories() map[string][]QueryError {
+	failed := make(map[string][]QueryError)
+	for _, err := range model.Errors {
+		failed[err.Category] = append(failed[err.Category], err)
+	}
+	return failed
+}
+
+// CarryOverFailedCategories keeps the previous PRs of every category that had a failed query, so a partial failure
+// neither blanks those categories out nor reports their PRs as removed.
+func CarryOverFailedCategories(next PRMenuModel, previous PRMenuModel) PRMenuModel {
+	failed := next.FailedCategories()
+	if len(failed) == 0 {
+		return next
+	}
+	merged := PRMenuModel{
+		Shown:  make(map[string][]github.PullRequest, len(next.Shown)),
+		Hidden: make(map[string][]github.PullRequest, len(next.Hidden)),
+		Errors: next.Errors,
+	}
+	for category, prs := range next.Shown {
+		merged.Shown[category] = prs
+	}
+	for category, prs := range next.Hidden {
+		merged.Hidden[category] = prs
+	}
+	for category := range failed {
+		if prs, found := previous.Shown[category]; found {
+			merged.Shown[category] = prs
+		}
+		if prs, found := previous.Hidden[category]; found {
+			merged.Hidden[category] = prs
+		}
+	}
+	return merged
+}
+
+type ErrorEntry struct {
+	Time     time.Time
+	Category string
+	Query    string
+	Err      error
+}
+
+// ErrorHistory keeps the most recent query errors across refreshes.
+type ErrorHistory struct {
+	mutex   sync.Mutex
+	entries *slices.Ring[ErrorEntry]
+}
+
+func NewErrorHistory(capacity int) *ErrorHistory {
+	return &ErrorHistory{entries: slices.NewRing[ErrorEntry](capacity)}
+}
+
+func (h *ErrorHistory) Add(now time.Time, errs ...error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, err := range errs {
+		entry := ErrorEntry{Time: now, Err: err}
+		var queryError QueryError
+		if errors.As(err, &queryError) {
+			entry.Category = queryError.Category
+			entry.Query = queryError.Query
+		}
+		h.entries.Push(entry)
+	}
+}
+
+// Entries returns the kept errors, most recent first.
+func (h *ErrorHistory) Entries() []ErrorEntry {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	oldestFirst := h.entries.Items()
+	entries := make([]ErrorEntry, 0, len(oldestFirst))
+	for i := len(oldestFirst) - 1; i >= 0; i-- {
+		entries = append(entries, oldestFirst[i])
+	}
+	return entries
+}
+
+// Offline tells whether a fetch failed only because GitHub could not be reached at all.
+func Offline(errs []error) bool {
+	if len(errs) == 0 {
+		return false
+	}
+	return !slices.Any(errs, func(err error) bool {
+		var networkErr *github.NetworkError
+		return !errors.As(err, &networkErr)
+	})
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"macos-gh-bar/config"
+	"macos-gh-bar/github"
+	"sort"
+	"time"
+)
+
+// PRGroup is a subsection of a category, its title is empty when the category is not grouped.
+type PRGroup struct {
+	Title string
+	PRs   []github.PullRequest
+}
+
+const (
+	ageToday    = "Today"
+	ageThisWeek = "This week"
+	ageOlder    = "Older"
+)
+
+var reviewDecisionTitles = map[github.ReviewState]string{
+	github.ReviewStateChangesRequested: "Changes requested",
+	github.ReviewStatePending:          "Review pending",
+	github.ReviewStateCommented:        "Commented",
+	github.ReviewStateApproved:         "Approved",
+	github.ReviewStateUnknown:          "Unknown review state",
+}
+
+// GroupPRs splits the PRs along one of the config.GroupBy dimensions, keeping their order within each group. A PR
+// with several labels is listed under each of them. Age buckets use the creation time relative to now.
+func GroupPRs(prs []github.PullRequest, by string, now time.Time) []PRGroup {
+	if by == config.GroupByNone {
+		return []PRGroup{{PRs: prs}}
+	}
+	byTitle := make(map[string][]github.PullRequest)
+	for _, pr := range prs {
+		for _, title := range groupTitles(pr, by, now) {
+			byTitle[title] = append(byTitle[title], pr)
+		}
+	}
+	titles := make([]string, 0, len(byTitle))
+	for title := range byTitle {
+		titles = append(titles, title)
+	}
+	sort.Slice(titles, func(i, j int) bool {
+		return groupRank(titles[i], by) < groupRank(titles[j], by) ||
+			groupRank(titles[i], by) == groupRank(titles[j], by) && titles[i] < titles[j]
+	})
+	groups := make([]PRGroup, 0, len(titles))
+	for _, title := range titles {
+		groups = append(groups, PRGroup{Title: title, PRs: byTitle[title]})
+	}
+	return groups
+}
+
+func groupTitles(pr github.PullRequest, by string, now time.Time) []string {
+	switch by {
+	case config.GroupByAuthor:
+		return []string{pr.Author}
+	case config.GroupByLabel:
+		if len(pr.Labels) == 0 {
+			return []string{"No label"}
+		}
+		return pr.Labels
+	case config.GroupByBaseBranch:
+		if pr.BaseBranch == "" {
+			return []string{"Unknown base branch"}
+		}
+		return []string{pr.BaseBranch}
+	case config.GroupByReviewDecision:
+		return []string{reviewDecisionTitles[pr.ReviewState]}
+	case config.GroupByAge:
+		return []string{ageBucket(pr.CreatedAt, now)}
+	}
+	return []string{pr.Repository}
+}
+
+func ageBucket(created time.Time, now time.Time) string {
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case !created.Before(startOfToday):
+		return ageToday
+	case created.After(now.AddDate(0, 0, -7)):
+		return ageThisWeek
+	}
+	return ageOlder
+}
+
+// groupRank orders review decisions by attention needed and age buckets from newest, other groups alphabetically.
+func groupRank(title string, by string) int {
+	switch by {
+	case config.GroupByReviewDecision:
+		for state, stateTitle := range reviewDecisionTitles {
+			if stateTitle == title {
+				return reviewStateRanks[state]
+			}
+		}
+	case config.GroupByAge:
+		switch title {
+		case ageToday:
+			return 0
+		case ageThisWeek:
+			return 1
+		}
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"fmt"
+	"macos-gh-bar/config"
+	"macos-gh-bar/github"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// describeGroups lists each group in order as its title followed by its PRs.
+func describeGroups(groups []PRGroup) []string {
+	described := make([]string, 0, len(groups))
+	for _, group := range groups {
+		described = append(described, fmt.Sprintf("%s: %s", group.Title, strings.Join(describePRs(group.PRs), " ")))
+	}
+	return described
+}
+
+func TestGroupPRs(t *testing.T) {
+	// a Wednesday, so the week started before the start of today
+	now := time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC)
+	a := pr("acme/web", 1)
+	a.Author, a.BaseBranch, a.Labels = "carol", "main", []string{"bug", "ui"}
+	a.ReviewState, a.CreatedAt = github.ReviewStateApproved, now.Add(-time.Hour)
+	b := pr("acme/api", 2)
+	b.Author, b.BaseBranch, b.Labels = "alice", "release", []string{"bug"}
+	b.ReviewState, b.CreatedAt = github.ReviewStateChangesRequested, time.Date(2026, time.March, 3, 23, 0, 0, 0, time.UTC)
+	c := pr("acme/api", 3)
+	c.Author = "carol"
+	c.ReviewState, c.CreatedAt = github.ReviewStatePending, now.AddDate(0, 0, -7)
+	d := pr("acme/web", 4)
+	d.Author, d.BaseBranch = "bob", "main"
+	d.CreatedAt = time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC)
+	prs := []github.PullRequest{a, b, c, d}
+
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{
+			by: config.GroupByRepository,
+			want: []string{
+				"acme/api: acme/api#2 acme/api#3",
+				"acme/web: acme/web#1 acme/web#4",
+			},
+		},
+		{
+			by: config.GroupByAuthor,
+			want: []string{
+				"alice: acme/api#2",
+				"bob: acme/web#4",
+				"carol: acme/web#1 acme/api#3",
+			},
+		},
+		{
+			by: config.GroupByLabel,
+			want: []string{
+				"No label: acme/api#3 acme/web#4",
+				"bug: acme/web#1 acme/api#2",
+				"ui: acme/web#1",
+			},
+		},
+		{
+			by: config.GroupByBaseBranch,
+			want: []string{
+				"Unknown base branch: acme/api#3",
+				"main: acme/web#1 acme/web#4",
+				"release: acme/api#2",
+			},
+		},
+		{
+			by: config.GroupByReviewDecision,
+			want: []string{
+				"Changes requested: acme/api#2",
+				"Review pending: acme/api#3",
+				"Approved: acme/web#1",
+				"Unknown review state: acme/web#4",
+			},
+		},
+		{
+			by: config.GroupByAge,
+			want: []string{
+				"Today: acme/web#1 acme/web#4",
+				"This week: acme/api#2",
+				"Older: acme/api#3",
+			},
+		},
+		{
+			by: config.GroupByNone,
+			want: []string{
+				": acme/web#1 acme/api#2 acme/api#3 acme/web#4",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.by, func(t *testing.T) {
+			if got := describeGroups(GroupPRs(prs, test.by, now)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GroupPRs(%s) = %q, want %q", test.by, got, test.want)
+			}
+		})
+	}
+}
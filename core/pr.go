@@ -2,41 +2,119 @@ package core
 
 import (
 	"fmt"
+	"log/slog"
+	"macos-gh-bar/config"
 	"macos-gh-bar/github"
-	"macos-gh-bar/native"
 	"macos-gh-bar/slices"
-	"macos-gh-bar/view"
+	"sync"
 	"time"
 )
 
 type PRMenuModel struct {
 	Hidden map[string][]github.PullRequest
 	Shown  map[string][]github.PullRequest
+	// Errors holds the failed queries of the fetch that produced this model.
+	Errors []QueryError
 }
 
-func FetchPRs(ghops *github.GhOperations, config view.Configuration) (PRMenuModel, []error) {
+func (model PRMenuModel) All() map[string][]github.PullRequest {
+	all := make(map[string][]github.PullRequest, len(model.Shown))
+	for category, prs := range model.Shown {
+		all[category] = append(all[category], prs...)
+	}
+	for category, prs := range model.Hidden {
+		all[category] = append(all[category], prs...)
+	}
+	return all
+}
+
+// HideRule hides PRs on top of the configured hide_prs filters, e.g. snoozed PRs, and is evaluated on every fetch.
+type HideRule func(pr github.PullRequest, category string) bool
+
+// CategoryCounts counts the PRs of each category.
+func CategoryCounts(categoryPRs map[string][]github.PullRequest) map[string]int {
+	counts := make(map[string]int, len(categoryPRs))
+	for category, prs := range categoryPRs {
+		counts[category] = len(prs)
+	}
+	return counts
+}
+
+// perPRConcurrency bounds the per-PR calls in flight across all categories, as bursts of concurrent requests trip the
+// secondary rate limit of GitHub.
+const perPRConcurrency = 6
+
+func FetchPRs(ghops *github.GhOperations, conf config.Configuration, hideRules ...HideRule) (PRMenuModel, []error) {
+	perPRSlots := make(chan struct{}, perPRConcurrency)
 	var searchErrors []error
-	prs := slices.MapParallelMany(config.QueryGroups, func(category string, queries []string) []github.PullRequest {
-		categoryPRs := slices.ParallelMany(queries, func(query string) []github.PullRequest {
+	var errorsMutex sync.Mutex
+	addError := func(err QueryError) {
+		errorsMutex.Lock()
+		defer errorsMutex.Unlock()
+		searchErrors = append(searchErrors, err)
+	}
+	prs := slices.MapParallelMany(conf.QueryGroups, func(category string, group config.QueryGroup) []github.PullRequest {
+		searchedPRs := slices.ParallelMany(group.Queries, func(query string) []github.PullRequest {
 			start := time.Now()
 			queriedPRs, err := ghops.SearchIssues(query)
-			native.FNSLog("Ran Github query %s in %s", query, time.Since(start))
-			queriedPRs = slices.Filter(queriedPRs, func(pr github.PullRequest) bool {
-				return config.MatchIgnoredPRs(pr) == false
-			})
+			slog.Info("Ran Github query", "category", category, "query", query, "duration", time.Since(start), "count", len(queriedPRs))
 			if err != nil {
-				searchErrors = append(searchErrors, fmt.Errorf("error searching PRs matching query %s: %w", query, err))
+				addError(QueryError{Category: category, Query: query, Err: fmt.Errorf("error searching PRs matching query %s: %w", query, err)})
 			}
 			return queriedPRs
 		})
-		return categoryPRs
+		listedPRs := slices.ParallelMany(group.Repos, func(repository string) []github.PullRequest {
+			start := time.Now()
+			repositoryPRs, err := ghops.ListOpenPRs(repository)
+			slog.Info("Listed open PRs of repository", "category", category, "repository", repository, "duration", time.Since(start), "count", len(repositoryPRs))
+			if err != nil {
+				addError(QueryError{Category: category, Query: "repo:" + repository, Err: fmt.Errorf("error listing PRs of repository %s: %w", repository, err)})
+			}
+			return repositoryPRs
+		})
+		groupPRs := slices.Filter(distinctPRs(append(listedPRs, searchedPRs...)), func(pr github.PullRequest) bool {
+			return conf.MatchIgnoredPRs(pr) == false && group.MatchFilters(pr)
+		})
+		if conf.FetchPRStatus {
+			groupPRs = fetchPRsStatus(ghops, groupPRs, perPRSlots)
+		}
+		if group.SLA != nil && group.SLA.UseReviewRequested {
+			groupPRs = fetchPRsReviewRequestedAt(ghops, groupPRs, perPRSlots)
+		}
+		return groupPRs
 	})
+	model := SplitHiddenPRs(prs, conf, hideRules...)
+	for _, err := range searchErrors {
+		model.Errors = append(model.Errors, err.(QueryError))
+	}
+	return model, searchErrors
+}
+
+// distinctPRs keeps the first listing of each PR, PRs found by several queries and repositories of a category are
+// listed once. Listed PRs come first as they carry the head branch that search results lack.
+func distinctPRs(prs []github.PullRequest) []github.PullRequest {
+	listed := make(map[string]bool, len(prs))
+	distinct := make([]github.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		key := fmt.Sprintf("%s#%d", pr.Repository, pr.Number)
+		if !listed[key] {
+			listed[key] = true
+			distinct = append(distinct, pr)
+		}
+	}
+	return distinct
+}
+
+// SplitHiddenPRs separates the PRs of every category matched by the hide_prs filters or any hide rule.
+func SplitHiddenPRs(prs map[string][]github.PullRequest, conf config.Configuration, hideRules ...HideRule) PRMenuModel {
 	prsToHide := make(map[string][]github.PullRequest, len(prs))
 	prsToShow := make(map[string][]github.PullRequest, len(prs))
 	for category, queries := range prs {
 		prsToShow[category] = make([]github.PullRequest, 0)
 		toHide, toShow := slices.Split(queries, func(pr github.PullRequest) bool {
-			return config.MatchHidePRs(pr, category)
+			return conf.MatchHidePRs(pr, category) || slices.Any(hideRules, func(rule HideRule) bool {
+				return rule(pr, category)
+			})
 		})
 		prsToHide[category] = append(prsToHide[category], toHide...)
 		prsToShow[category] = append(prsToShow[category], toShow...)
@@ -44,5 +122,31 @@ func FetchPRs(ghops *github.GhOperations, config view.Configuration) (PRMenuMode
 	return PRMenuModel{
 		Hidden: prsToHide,
 		Shown:  prsToShow,
-	}, searchErrors
+	}
+}
+
+// fetchPRsStatus fetches the status of the PRs in parallel, each holding one of slots while fetching.
+func fetchPRsStatus(ghops *github.GhOperations, prs []github.PullRequest, slots chan struct{}) []github.PullRequest {
+	return slices.ParallelMany(prs, func(pr github.PullRequest) []github.PullRequest {
+		slots <- struct{}{}
+		defer func() { <-slots }()
+		withStatus, err := ghops.FetchStatus(pr)
+		if err != nil {
+			slog.Warn("Error fetching status of PR", "repository", pr.Repository, "number", pr.Number, "error", err)
+		}
+		return []github.PullRequest{withStatus}
+	})
+}
+
+// fetchPRsReviewRequestedAt shares the slots of fetchPRsStatus, the two passes may run at once for different categories.
+func fetchPRsReviewRequestedAt(ghops *github.GhOperations, prs []github.PullRequest, slots chan struct{}) []github.PullRequest {
+	return slices.ParallelMany(prs, func(pr github.PullRequest) []github.PullRequest {
+		slots <- struct{}{}
+		defer func() { <-slots }()
+		withRequest, err := ghops.FetchReviewRequestedAt(pr)
+		if err != nil {
+			slog.Warn("Error fetching review request time of PR", "repository", pr.Repository, "number", pr.Number, "error", err)
+		}
+		return []github.PullRequest{withRequest}
+	})
 }
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"macos-gh-bar/github"
+	"reflect"
+	"testing"
+)
+
+func TestDistinctPRs(t *testing.T) {
+	listed := pr("acme/api", 1)
+	listed.HeadBranch = "fix-login"
+	tests := []struct {
+		name string
+		prs  []github.PullRequest
+		want []string
+	}{
+		{name: "no PRs", prs: nil, want: []string{}},
+		{name: "distinct PRs", prs: []github.PullRequest{pr("acme/api", 1), pr("acme/web", 1), pr("acme/api", 2)}, want: []string{"acme/api#1", "acme/web#1", "acme/api#2"}},
+		{name: "found by two queries", prs: []github.PullRequest{pr("acme/api", 2), pr("acme/api", 1), pr("acme/api", 2)}, want: []string{"acme/api#2", "acme/api#1"}},
+		{name: "listed and searched", prs: []github.PullRequest{listed, pr("acme/web", 3), pr("acme/api", 1)}, want: []string{"acme/api#1", "acme/web#3"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := describePRs(distinctPRs(test.prs)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("distinctPRs() = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	if got := distinctPRs([]github.PullRequest{listed, pr("acme/api", 1)}); got[0].HeadBranch != "fix-login" {
+		t.Errorf("distinctPRs() kept %+v, want the first listing with its head branch", got[0])
+	}
+}
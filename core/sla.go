@@ -0,0 +1,80 @@
+package core
+
+import (
+	"macos-gh-bar/config"
+	"macos-gh-bar/github"
+	"time"
+)
+
+type Staleness string
+
+const (
+	StalenessUnknown Staleness = ""
+	StalenessFresh   Staleness = "fresh"
+	StalenessAging   Staleness = "aging"
+	StalenessOverdue Staleness = "overdue"
+)
+
+// BusinessCalendar counts only the time of working days that are not holidays.
+type BusinessCalendar struct {
+	WorkingDays map[time.Weekday]bool
+	Holidays    map[string]bool
+}
+
+// NewBusinessCalendar falls back to Monday to Friday without holidays on a configuration that failed validation.
+func NewBusinessCalendar(conf config.Configuration) BusinessCalendar {
+	workingDays, err := conf.WorkingWeekdays()
+	if err != nil {
+		workingDays, _ = config.Configuration{}.WorkingWeekdays()
+	}
+	holidays, _ := conf.HolidayDates()
+	return BusinessCalendar{WorkingDays: workingDays, Holidays: holidays}
+}
+
+func (c BusinessCalendar) businessDay(day time.Time) bool {
+	return c.WorkingDays[day.Weekday()] && !c.Holidays[day.Format(time.DateOnly)]
+}
+
+// Elapsed sums the time between from and to falling on business days, in the time zone of to.
+func (c BusinessCalendar) Elapsed(from, to time.Time) time.Duration {
+	from = from.In(to.Location())
+	var elapsed time.Duration
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, to.Location())
+	for day.Before(to) {
+		next := day.AddDate(0, 0, 1)
+		if c.businessDay(day) {
+			start, end := day, next
+			if from.After(start) {
+				start = from
+			}
+			if to.Before(end) {
+				end = to
+			}
+			if end.After(start) {
+				elapsed += end.Sub(start)
+			}
+		}
+		day = next
+	}
+	return elapsed
+}
+
+// StalenessOf compares the business time the PR has been waiting against the SLA thresholds, unknown when the PR
+// carries no usable timestamp.
+func StalenessOf(pr github.PullRequest, sla config.SLA, calendar BusinessCalendar, now time.Time) Staleness {
+	since := pr.UpdatedAt
+	if sla.UseReviewRequested && !pr.ReviewRequestedAt.IsZero() {
+		since = pr.ReviewRequestedAt
+	}
+	if since.IsZero() {
+		return StalenessUnknown
+	}
+	waited := calendar.Elapsed(since, now)
+	switch {
+	case sla.OverdueAfterHours > 0 && waited >= time.Duration(sla.OverdueAfterHours)*time.Hour:
+		return StalenessOverdue
+	case sla.AgingAfterHours > 0 && waited >= time.Duration(sla.AgingAfterHours)*time.Hour:
+		return StalenessAging
+	}
+	return StalenessFresh
+}
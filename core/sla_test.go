@@ -0,0 +1,111 @@
+package core
+
+import (
+	"macos-gh-bar/config"
+	"macos-gh-bar/github"
+	"testing"
+	"time"
+)
+
+// at is a time on March 2026, the 6th is a Friday and the 9th a Monday.
+func at(day int, hour int, minute int) time.Time {
+	return time.Date(2026, time.March, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestElapsed(t *testing.T) {
+	calendar := NewBusinessCalendar(config.Configuration{Holidays: []string{"2026-03-10"}})
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want time.Duration
+	}{
+		{name: "within a working day", from: at(4, 9, 0), to: at(4, 17, 30), want: 8*time.Hour + 30*time.Minute},
+		{name: "across working days", from: at(4, 22, 0), to: at(5, 2, 0), want: 4 * time.Hour},
+		{name: "over a weekend", from: at(6, 18, 0), to: at(9, 6, 0), want: 12 * time.Hour},
+		{name: "starting on a Saturday", from: at(7, 15, 0), to: at(9, 3, 0), want: 3 * time.Hour},
+		{name: "ending on a Sunday", from: at(6, 20, 0), to: at(8, 10, 0), want: 4 * time.Hour},
+		{name: "within a weekend", from: at(7, 1, 0), to: at(8, 23, 0), want: 0},
+		{name: "over a holiday", from: at(9, 12, 0), to: at(11, 12, 0), want: 24 * time.Hour},
+		{name: "starting on a holiday", from: at(10, 8, 0), to: at(11, 1, 0), want: time.Hour},
+		{name: "ending on a holiday", from: at(9, 23, 0), to: at(10, 12, 0), want: time.Hour},
+		{name: "a whole week", from: at(2, 0, 0), to: at(9, 0, 0), want: 5 * 24 * time.Hour},
+		{name: "same instant", from: at(4, 9, 0), to: at(4, 9, 0), want: 0},
+		{name: "from after to on the same day", from: at(4, 17, 0), to: at(4, 9, 0), want: 0},
+		{name: "from after to on another day", from: at(5, 9, 0), to: at(4, 9, 0), want: 0},
+		{
+			name: "from in another time zone",
+			from: time.Date(2026, time.March, 6, 23, 0, 0, 0, time.FixedZone("UTC-2", -2*60*60)),
+			to:   at(9, 2, 0),
+			want: 2 * time.Hour,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := calendar.Elapsed(test.from, test.to); got != test.want {
+				t.Errorf("Elapsed(%s, %s) = %s, want %s", test.from, test.to, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewBusinessCalendar(t *testing.T) {
+	weekend := NewBusinessCalendar(config.Configuration{WorkingDays: []string{"sat", "sun"}})
+	if got := weekend.Elapsed(at(6, 0, 0), at(9, 0, 0)); got != 48*time.Hour {
+		t.Errorf("Elapsed over a weekend with weekend working days = %s, want 48h", got)
+	}
+	invalid := NewBusinessCalendar(config.Configuration{WorkingDays: []string{"someday"}})
+	if got := invalid.Elapsed(at(6, 0, 0), at(9, 0, 0)); got != 24*time.Hour {
+		t.Errorf("Elapsed over a weekend with invalid working days = %s, want 24h", got)
+	}
+}
+
+func TestStalenessOf(t *testing.T) {
+	calendar := NewBusinessCalendar(config.Configuration{})
+	now := at(9, 12, 0)
+	sla := config.SLA{AgingAfterHours: 8, OverdueAfterHours: 24}
+	updated := func(updatedAt time.Time, reviewRequestedAt time.Time) github.PullRequest {
+		pr := pr("acme/api", 1)
+		pr.UpdatedAt, pr.ReviewRequestedAt = updatedAt, reviewRequestedAt
+		return pr
+	}
+	tests := []struct {
+		name string
+		pr   github.PullRequest
+		sla  config.SLA
+		want Staleness
+	}{
+		{name: "fresh", pr: updated(at(9, 6, 0), time.Time{}), sla: sla, want: StalenessFresh},
+		{name: "aging at the threshold", pr: updated(at(9, 4, 0), time.Time{}), sla: sla, want: StalenessAging},
+		{name: "aging after the weekend", pr: updated(at(6, 20, 0), time.Time{}), sla: sla, want: StalenessAging},
+		{name: "overdue", pr: updated(at(6, 10, 0), time.Time{}), sla: sla, want: StalenessOverdue},
+		{name: "no timestamp", pr: updated(time.Time{}, time.Time{}), sla: sla, want: StalenessUnknown},
+		{name: "no thresholds", pr: updated(at(2, 0, 0), time.Time{}), sla: config.SLA{}, want: StalenessFresh},
+		{name: "only overdue threshold", pr: updated(at(9, 0, 0), time.Time{}), sla: config.SLA{OverdueAfterHours: 24}, want: StalenessFresh},
+		{
+			name: "review request ignored by default",
+			pr:   updated(at(6, 10, 0), at(9, 10, 0)),
+			sla:  sla,
+			want: StalenessOverdue,
+		},
+		{
+			name: "counted from the review request",
+			pr:   updated(at(6, 10, 0), at(9, 10, 0)),
+			sla:  config.SLA{AgingAfterHours: 8, OverdueAfterHours: 24, UseReviewRequested: true},
+			want: StalenessFresh,
+		},
+		{
+			name: "update used without a review request",
+			pr:   updated(at(6, 10, 0), time.Time{}),
+			sla:  config.SLA{AgingAfterHours: 8, OverdueAfterHours: 24, UseReviewRequested: true},
+			want: StalenessOverdue,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := StalenessOf(test.pr, test.sla, calendar, now); got != test.want {
+				t.Errorf("StalenessOf() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
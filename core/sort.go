@@ -0,0 +1,65 @@
+package core
+
+import (
+	"macos-gh-bar/config"
+	"macos-gh-bar/github"
+	"sort"
+	"strings"
+)
+
+// reviewStateRanks orders review states by how much they need attention, unknown last.
+var reviewStateRanks = map[github.ReviewState]int{
+	github.ReviewStateChangesRequested: 0,
+	github.ReviewStatePending:          1,
+	github.ReviewStateCommented:        2,
+	github.ReviewStateApproved:         3,
+	github.ReviewStateUnknown:          4,
+}
+
+var ciStateRanks = map[github.CIState]int{
+	github.CIStateFailure: 0,
+	github.CIStatePending: 1,
+	github.CIStateSuccess: 2,
+	github.CIStateUnknown: 3,
+}
+
+// SortPRs returns the PRs ordered by the keys in turn, then by repository and number so the order does not depend on
+// which query answered first.
+func SortPRs(prs []github.PullRequest, keys []config.SortKey) []github.PullRequest {
+	sorted := append([]github.PullRequest(nil), prs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, key := range keys {
+			if c := comparePRs(sorted[i], sorted[j], key.Field); c != 0 {
+				if key.Descending {
+					return c > 0
+				}
+				return c < 0
+			}
+		}
+		if c := strings.Compare(sorted[i].Repository, sorted[j].Repository); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Number < sorted[j].Number
+	})
+	return sorted
+}
+
+func comparePRs(a, b github.PullRequest, field string) int {
+	switch field {
+	case config.SortUpdated:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case config.SortCreated:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case config.SortNumber:
+		return a.Number - b.Number
+	case config.SortTitle:
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case config.SortAuthor:
+		return strings.Compare(strings.ToLower(a.Author), strings.ToLower(b.Author))
+	case config.SortReviewState:
+		return reviewStateRanks[a.ReviewState] - reviewStateRanks[b.ReviewState]
+	case config.SortCIState:
+		return ciStateRanks[a.CIState] - ciStateRanks[b.CIState]
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+	"macos-gh-bar/config"
+	"macos-gh-bar/github"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func describePRs(prs []github.PullRequest) []string {
+	described := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		described = append(described, fmt.Sprintf("%s#%d", pr.Repository, pr.Number))
+	}
+	return described
+}
+
+func TestSortPRs(t *testing.T) {
+	base := time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC)
+	a := pr("acme/web", 3)
+	a.Title, a.Author = "beta", "Carol"
+	a.CreatedAt, a.UpdatedAt = base.Add(-3*time.Hour), base.Add(-1*time.Hour)
+	a.ReviewState, a.CIState = github.ReviewStateApproved, github.CIStateFailure
+	b := pr("acme/api", 7)
+	b.Title, b.Author = "Alpha", "bob"
+	b.CreatedAt, b.UpdatedAt = base.Add(-1*time.Hour), base.Add(-3*time.Hour)
+	b.ReviewState, b.CIState = github.ReviewStateChangesRequested, github.CIStateSuccess
+	c := pr("acme/api", 2)
+	c.Title, c.Author = "gamma", "alice"
+	c.CreatedAt, c.UpdatedAt = base.Add(-2*time.Hour), base.Add(-2*time.Hour)
+	c.CIState = github.CIStatePending
+	prs := []github.PullRequest{a, b, c}
+
+	tests := []struct {
+		name string
+		sort []string
+		want []string
+	}{
+		{name: "no keys falls back to repository and number", want: []string{"acme/api#2", "acme/api#7", "acme/web#3"}},
+		{name: "updated defaults to most recent first", sort: []string{"updated"}, want: []string{"acme/web#3", "acme/api#2", "acme/api#7"}},
+		{name: "updated ascending", sort: []string{"updated asc"}, want: []string{"acme/api#7", "acme/api#2", "acme/web#3"}},
+		{name: "created", sort: []string{"created"}, want: []string{"acme/web#3", "acme/api#2", "acme/api#7"}},
+		{name: "created descending", sort: []string{"created desc"}, want: []string{"acme/api#7", "acme/api#2", "acme/web#3"}},
+		{name: "number", sort: []string{"number"}, want: []string{"acme/api#2", "acme/web#3", "acme/api#7"}},
+		{name: "number descending", sort: []string{"number desc"}, want: []string{"acme/api#7", "acme/web#3", "acme/api#2"}},
+		{name: "title ignores case", sort: []string{"title"}, want: []string{"acme/api#7", "acme/web#3", "acme/api#2"}},
+		{name: "title descending", sort: []string{"title desc"}, want: []string{"acme/api#2", "acme/web#3", "acme/api#7"}},
+		{name: "author ignores case", sort: []string{"author"}, want: []string{"acme/api#2", "acme/api#7", "acme/web#3"}},
+		{name: "author descending", sort: []string{"author desc"}, want: []string{"acme/web#3", "acme/api#7", "acme/api#2"}},
+		{name: "review state by attention needed, unknown last", sort: []string{"review_state"}, want: []string{"acme/api#7", "acme/web#3", "acme/api#2"}},
+		{name: "review state descending", sort: []string{"review_state desc"}, want: []string{"acme/api#2", "acme/web#3", "acme/api#7"}},
+		{name: "ci state failures first", sort: []string{"ci_state"}, want: []string{"acme/web#3", "acme/api#2", "acme/api#7"}},
+		{name: "ci state descending", sort: []string{"ci_state desc"}, want: []string{"acme/api#7", "acme/api#2", "acme/web#3"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := config.QueryGroup{Sort: test.sort}.SortKeys()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describePRs(SortPRs(prs, keys)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("SortPRs(%q) = %q, want %q", test.sort, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSortPRsTiebreak(t *testing.T) {
+	prs := []github.PullRequest{pr("acme/web", 1), pr("acme/api", 9), pr("acme/api", 4)}
+	for i := range prs {
+		prs[i].Author = "alice"
+	}
+	keys := []config.SortKey{{Field: config.SortAuthor}}
+	want := []string{"acme/api#4", "acme/api#9", "acme/web#1"}
+	if got := describePRs(SortPRs(prs, keys)); !reflect.DeepEqual(got, want) {
+		t.Errorf("SortPRs() = %q, want %q", got, want)
+	}
+	// the tiebreak stays ascending whatever the direction of the keys
+	keys[0].Descending = true
+	if got := describePRs(SortPRs(prs, keys)); !reflect.DeepEqual(got, want) {
+		t.Errorf("SortPRs() descending = %q, want %q", got, want)
+	}
+	if got := describePRs(prs); !reflect.DeepEqual(got, []string{"acme/web#1", "acme/api#9", "acme/api#4"}) {
+		t.Errorf("SortPRs() reordered its input to %q", got)
+	}
+}
+
+func TestSortPRsSecondaryKey(t *testing.T) {
+	a, b, c := pr("acme/api", 1), pr("acme/api", 2), pr("acme/api", 3)
+	a.Author, b.Author, c.Author = "bob", "alice", "bob"
+	a.Title, b.Title, c.Title = "Zed", "Middle", "apple"
+	keys, err := config.QueryGroup{Sort: []string{"author", "title"}}.SortKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"acme/api#2", "acme/api#3", "acme/api#1"}
+	if got := describePRs(SortPRs([]github.PullRequest{a, b, c}, keys)); !reflect.DeepEqual(got, want) {
+		t.Errorf("SortPRs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Score rates how well query matches text, ignoring case. A substring scores above any scattered match and earlier
+// substrings score higher, scattered matches score higher on consecutive characters and word starts. ok is false
+// unless every character of query appears in text in order.
+func Score(query string, text string) (int, bool) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	text = strings.ToLower(text)
+	if query == "" {
+		return 0, true
+	}
+	if index := strings.Index(text, query); index >= 0 {
+		return 1000 - len([]rune(text[:index])), true
+	}
+
+	queryRunes := []rune(query)
+	textRunes := []rune(text)
+	score := 0
+	matched := 0
+	previous := -2
+	for i, r := range textRunes {
+		if matched == len(queryRunes) {
+			break
+		}
+		if r != queryRunes[matched] {
+			continue
+		}
+		score += 10
+		if i == previous+1 {
+			score += 5
+		}
+		if i == 0 || !isWordRune(textRunes[i-1]) {
+			score += 8
+		}
+		previous = i
+		matched++
+	}
+	if matched < len(queryRunes) {
+		return 0, false
+	}
+	return score, true
+}
+
+// MatchAll is true when every whitespace separated term of query matches at least one of the fields.
+func MatchAll(query string, fields ...string) bool {
+	for _, term := range strings.Fields(query) {
+		found := false
+		for _, field := range fields {
+			if _, ok := Score(term, field); ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
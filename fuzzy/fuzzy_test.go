@@ -0,0 +1,76 @@
+package fuzzy
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		text      string
+		wantScore int
+		wantOK    bool
+	}{
+		{name: "empty query", query: "", text: "Fix login", wantScore: 0, wantOK: true},
+		{name: "blank query", query: "  ", text: "Fix login", wantScore: 0, wantOK: true},
+		{name: "prefix", query: "fix", text: "Fix login", wantScore: 1000, wantOK: true},
+		{name: "later substring", query: "login", text: "Fix login", wantScore: 996, wantOK: true},
+		{name: "case folding", query: "LOGIN", text: "fix Login", wantScore: 996, wantOK: true},
+		{name: "scattered on word starts", query: "fl", text: "Fix login", wantScore: 36, wantOK: true},
+		{name: "scattered consecutive", query: "fxl", text: "Fix login", wantScore: 46, wantOK: true},
+		{name: "out of order", query: "lf", text: "Fix login", wantOK: false},
+		{name: "missing character", query: "fiz", text: "Fix login", wantOK: false},
+		{name: "non-ASCII substring", query: "ÉTÉ", text: "Déjà l'été", wantScore: 993, wantOK: true},
+		{name: "non-ASCII prefix", query: "dé", text: "Déjà l'été", wantScore: 1000, wantOK: true},
+		{name: "non-ASCII word start", query: "jé", text: "Déjà l'été", wantScore: 28, wantOK: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			score, ok := Score(test.query, test.text)
+			if ok != test.wantOK || ok && score != test.wantScore {
+				t.Errorf("Score(%q, %q) = %d, %t, want %d, %t", test.query, test.text, score, ok, test.wantScore, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestScoreRanking(t *testing.T) {
+	tests := []struct {
+		query  string
+		better string
+		worse  string
+	}{
+		{query: "log", better: "Fix login", worse: "Fix lazy oauth grant"},
+		{query: "log", better: "login fix", worse: "Fix login"},
+		{query: "fl", better: "Fix login", worse: "fall"},
+		{query: "fl", better: "Fix login", worse: "Fix Xlogin"},
+	}
+	for _, test := range tests {
+		better, okBetter := Score(test.query, test.better)
+		worse, okWorse := Score(test.query, test.worse)
+		if !okBetter || !okWorse || better <= worse {
+			t.Errorf("Score(%q) ranks %q (%d, %t) below %q (%d, %t)", test.query, test.better, better, okBetter, test.worse, worse, okWorse)
+		}
+	}
+}
+
+func TestMatchAll(t *testing.T) {
+	fields := []string{"Fix login redirect", "acme/api", "alice", "#42"}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "", want: true},
+		{query: "login", want: true},
+		{query: "login alice", want: true},
+		{query: "alice #42", want: true},
+		{query: "ACME login", want: true},
+		{query: "login bob", want: false},
+		{query: "fix   api  ", want: true},
+		{query: "#43", want: false},
+	}
+	for _, test := range tests {
+		if got := MatchAll(test.query, fields...); got != test.want {
+			t.Errorf("MatchAll(%q) = %t, want %t", test.query, got, test.want)
+		}
+	}
+}
@@ -5,21 +5,34 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	gh "github.com/google/go-github/v74/github"
 )
 
 type PullRequest struct {
-	Number     int
-	Title      string
-	URL        string
-	Repository string
-	Author     string
-	Draft      bool
+	Number      int
+	Title       string
+	Body        string
+	URL         string
+	Repository  string
+	Author      string
+	Draft       bool
+	HeadSHA     string
+	HeadBranch  string
+	BaseBranch  string
+	Labels      []string
+	ReviewState ReviewState
+	CIState     CIState
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	// ReviewRequestedAt is only known once FetchReviewRequestedAt ran.
+	ReviewRequestedAt time.Time
 }
 
 type GhOperations struct {
-	client *gh.Client
+	client      *gh.Client
+	retryPolicy RetryPolicy
 }
 
 func NewGithubOperations(token string) *GhOperations {
@@ -31,7 +44,11 @@ func NewGithubOperations(token string) *GhOperations {
 
 func (ops *GhOperations) GetSelf() (string, error) {
 	ctx := context.Background()
-	user, _, err := ops.client.Users.Get(ctx, "")
+	var user *gh.User
+	err := ops.retry("user", func() (err error) {
+		user, _, err = ops.client.Users.Get(ctx, "")
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
@@ -41,7 +58,7 @@ func (ops *GhOperations) GetSelf() (string, error) {
 func (ops *GhOperations) CreatedOpenPRs() ([]PullRequest, error) {
 	prs, err := ops.SearchIssues("is:pr is:open author:@me archived:false")
 	if err != nil {
-		return nil, fmt.Errorf("failed to find open pull requests created by the user: %e", err)
+		return nil, fmt.Errorf("failed to find open pull requests created by the user: %w", err)
 	}
 	return prs, nil
 }
@@ -49,7 +66,7 @@ func (ops *GhOperations) CreatedOpenPRs() ([]PullRequest, error) {
 func (ops *GhOperations) ReviewerOpenPRs() ([]PullRequest, error) {
 	prs, err := ops.SearchIssues("is:pr is:open review-requested:@me archived:false")
 	if err != nil {
-		return nil, fmt.Errorf("failed to find open pull requests assigned to the user: %e", err)
+		return nil, fmt.Errorf("failed to find open pull requests assigned to the user: %w", err)
 	}
 	return prs, nil
 }
@@ -57,7 +74,7 @@ func (ops *GhOperations) ReviewerOpenPRs() ([]PullRequest, error) {
 func (ops *GhOperations) GetAllSelfOpenPRs() ([]PullRequest, error) {
 	prs, err := ops.SearchIssues("is:pr is:open (review-requested:@me or author:@me) archived:false")
 	if err != nil {
-		return nil, fmt.Errorf("failed to find open pull requests: %e", err)
+		return nil, fmt.Errorf("failed to find open pull requests: %w", err)
 	}
 	return prs, nil
 }
@@ -73,10 +90,13 @@ func (ops *GhOperations) SearchIssues(query string) ([]PullRequest, error) {
 func (ops *GhOperations) searchIssues(query string, options gh.SearchOptions) ([]PullRequest, error) {
 	client := ops.client
 	ctx := context.Background()
-	items, _, err := client.Search.Issues(ctx, query, &options)
-
+	var items *gh.IssuesSearchResult
+	err := ops.retry(query, func() (err error) {
+		items, _, err = client.Search.Issues(ctx, query, &options)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to find issues: %e", err)
+		return nil, fmt.Errorf("failed to find issues: %w", err)
 	}
 	createdPRs := make([]PullRequest, 0)
 	for _, issuePR := range items.Issues {
@@ -85,16 +105,79 @@ func (ops *GhOperations) searchIssues(query string, options gh.SearchOptions) ([
 			createdPRs = append(createdPRs, PullRequest{
 				Number:     issuePR.GetNumber(),
 				Title:      issuePR.GetTitle(),
+				Body:       issuePR.GetBody(),
 				Author:     issuePR.GetUser().GetLogin(),
 				Repository: repoName,
 				URL:        issuePR.GetHTMLURL(),
 				Draft:      issuePR.GetDraft(),
+				Labels:     labelNames(issuePR.Labels),
+				CreatedAt:  issuePR.GetCreatedAt().Time,
+				UpdatedAt:  issuePR.GetUpdatedAt().Time,
 			})
 		}
 	}
 	return createdPRs, nil
 }
 
+func (ops *GhOperations) ListOpenPRs(repository string) ([]PullRequest, error) {
+	owner, name, found := strings.Cut(repository, "/")
+	if !found || owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid repository %s, expected owner/name", repository)
+	}
+	prs, err := ops.listPullRequests(owner, name, gh.PullRequestListOptions{State: "open", Sort: "updated", Direction: "desc", ListOptions: gh.ListOptions{PerPage: 100}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open pull requests of repository %s: %w", repository, err)
+	}
+	return prs, nil
+}
+
+func (ops *GhOperations) listPullRequests(owner, name string, options gh.PullRequestListOptions) ([]PullRequest, error) {
+	client := ops.client
+	ctx := context.Background()
+	listedPRs := make([]PullRequest, 0)
+	for {
+		var pulls []*gh.PullRequest
+		var response *gh.Response
+		err := ops.retry("repo:"+owner+"/"+name, func() (err error) {
+			pulls, response, err = client.PullRequests.List(ctx, owner, name, &options)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		for _, pull := range pulls {
+			listedPRs = append(listedPRs, PullRequest{
+				Number:     pull.GetNumber(),
+				Title:      pull.GetTitle(),
+				Body:       pull.GetBody(),
+				Author:     pull.GetUser().GetLogin(),
+				Repository: pull.GetBase().GetRepo().GetFullName(),
+				URL:        pull.GetHTMLURL(),
+				Draft:      pull.GetDraft(),
+				HeadSHA:    pull.GetHead().GetSHA(),
+				HeadBranch: pull.GetHead().GetRef(),
+				BaseBranch: pull.GetBase().GetRef(),
+				Labels:     labelNames(pull.Labels),
+				CreatedAt:  pull.GetCreatedAt().Time,
+				UpdatedAt:  pull.GetUpdatedAt().Time,
+			})
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+	return listedPRs, nil
+}
+
+func labelNames(labels []*gh.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.GetName())
+	}
+	return names
+}
+
 func repositoryNameFromGhURL(url string) string {
 	if url[len(url)-1] == '/' {
 		url = url[:len(url)-1] // Remove trailing slash if present
@@ -105,3 +188,9 @@ func repositoryNameFromGhURL(url string) string {
 	}
 	return strings.Join(parts[len(parts)-2:], "/")
 }
+
+// Probe checks GitHub is reachable with a request that does not count against the rate limit, and is never retried.
+func (ops *GhOperations) Probe() error {
+	_, _, err := ops.client.RateLimit.Get(context.Background())
+	return classifyError("rate_limit", err)
+}
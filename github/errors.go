@@ -0,0 +1,156 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	gh "github.com/google/go-github/v74/github"
+)
+
+// AuthError is GitHub rejecting the token, usually because it expired or lacks a scope.
+type AuthError struct {
+	Query  string
+	Status int
+	Err    error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("github rejected the token (status %d) for %s: %v", e.Status, e.Query, e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// RateLimitError is a primary or secondary rate limit, Reset is zero when GitHub did not tell when it lifts.
+type RateLimitError struct {
+	Query  string
+	Status int
+	Reset  time.Time
+	Err    error
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("github rate limit exceeded (status %d) for %s: %v", e.Status, e.Query, e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidQueryError is a search query GitHub could not parse, or a repository that does not exist.
+type InvalidQueryError struct {
+	Query  string
+	Status int
+	Err    error
+}
+
+func (e *InvalidQueryError) Error() string {
+	return fmt.Sprintf("github refused %s (status %d): %v", e.Query, e.Status, e.Err)
+}
+
+func (e *InvalidQueryError) Unwrap() error {
+	return e.Err
+}
+
+// NetworkError is a request that never got a response, Status is always 0.
+type NetworkError struct {
+	Query  string
+	Status int
+	Err    error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("could not reach github for %s: %v", e.Query, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+type ServerError struct {
+	Query  string
+	Status int
+	Err    error
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("github failed (status %d) for %s: %v", e.Status, e.Query, e.Err)
+}
+
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// classifyError turns an error returned by the GitHub client into one of the typed errors above, and returns other
+// errors unchanged.
+func classifyError(query string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var rateLimitErr *gh.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return &RateLimitError{Query: query, Status: statusOf(rateLimitErr.Response), Reset: rateLimitErr.Rate.Reset.Time, Err: err}
+	}
+	var abuseErr *gh.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		classified := &RateLimitError{Query: query, Status: statusOf(abuseErr.Response), Err: err}
+		if abuseErr.RetryAfter != nil {
+			classified.Reset = time.Now().Add(*abuseErr.RetryAfter)
+		}
+		return classified
+	}
+	var responseErr *gh.ErrorResponse
+	if errors.As(err, &responseErr) {
+		status := statusOf(responseErr.Response)
+		switch {
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			return &AuthError{Query: query, Status: status, Err: err}
+		case status == http.StatusNotFound || status == http.StatusUnprocessableEntity:
+			return &InvalidQueryError{Query: query, Status: status, Err: err}
+		case status >= http.StatusInternalServerError:
+			return &ServerError{Query: query, Status: status, Err: err}
+		}
+		return err
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return &NetworkError{Query: query, Err: err}
+	}
+	return err
+}
+
+func statusOf(response *http.Response) int {
+	if response == nil {
+		return 0
+	}
+	return response.StatusCode
+}
+
+// UserMessage describes a typed error in a short sentence fit for the menu, falling back to the error itself.
+func UserMessage(err error) string {
+	var authErr *AuthError
+	var rateLimitErr *RateLimitError
+	var invalidQueryErr *InvalidQueryError
+	var networkErr *NetworkError
+	var serverErr *ServerError
+	switch {
+	case errors.As(err, &authErr):
+		return "GitHub token expired or invalid"
+	case errors.As(err, &rateLimitErr):
+		if rateLimitErr.Reset.IsZero() {
+			return "GitHub rate limit exceeded"
+		}
+		return fmt.Sprintf("GitHub rate limit exceeded until %s", rateLimitErr.Reset.Local().Format("15:04"))
+	case errors.As(err, &invalidQueryErr):
+		return fmt.Sprintf("Invalid query %s", invalidQueryErr.Query)
+	case errors.As(err, &networkErr):
+		return "GitHub unreachable, check the network"
+	case errors.As(err, &serverErr):
+		return fmt.Sprintf("GitHub server error (%d)", serverErr.Status)
+	}
+	return err.Error()
+}
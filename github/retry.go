@@ -0,0 +1,71 @@
+package github
+
+import (
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy retries transient failures of read-only calls: server errors, network errors and rate limits that lift
+// within MaxRateLimitWait. The zero policy makes a single attempt.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// MaxRateLimitWait is kept apart from MaxDelay as secondary rate limits usually ask to wait a minute.
+	MaxRateLimitWait time.Duration
+}
+
+// WithRetryPolicy makes every call of ops retry according to policy.
+func (ops *GhOperations) WithRetryPolicy(policy RetryPolicy) *GhOperations {
+	ops.retryPolicy = policy
+	return ops
+}
+
+// retry runs call until it succeeds, fails with an error that is not transient or runs out of attempts, and returns
+// the last error classified.
+func (ops *GhOperations) retry(query string, call func() error) error {
+	attempts := max(ops.retryPolicy.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := classifyError(query, call())
+		if err == nil || attempt >= attempts {
+			return err
+		}
+		wait, retryable := ops.retryPolicy.backoff(attempt, err)
+		if !retryable {
+			return err
+		}
+		slog.Warn("Retrying GitHub call", "query", query, "attempt", attempt, "wait", wait, "error", err)
+		time.Sleep(wait)
+	}
+}
+
+// backoff tells how long to wait before the next attempt, exponential with full jitter unless GitHub told when a rate
+// limit lifts.
+func (p RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	var rateLimitErr *RateLimitError
+	var serverErr *ServerError
+	var networkErr *NetworkError
+	switch {
+	case errors.As(err, &rateLimitErr):
+		if !rateLimitErr.Reset.IsZero() {
+			wait := time.Until(rateLimitErr.Reset)
+			if wait > p.MaxRateLimitWait {
+				return 0, false
+			}
+			return max(wait, 0), true
+		}
+	case errors.As(err, &serverErr), errors.As(err, &networkErr):
+	default:
+		return 0, false
+	}
+	ceiling := p.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0, true
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1)), true
+}
@@ -0,0 +1,173 @@
+package github
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v74/github"
+)
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, MaxRateLimitWait: 5 * time.Second}
+
+// countingTransport counts the requests leaving the client, including those failing to connect.
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func newTestOperations(t *testing.T, baseURL string, policy RetryPolicy) (*GhOperations, *countingTransport) {
+	t.Helper()
+	transport := &countingTransport{}
+	client := gh.NewClient(&http.Client{Transport: transport})
+	parsed, err := url.Parse(baseURL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = parsed
+	return (&GhOperations{client: client}).WithRetryPolicy(policy), transport
+}
+
+// respondInTurn answers the n-th request with the n-th handler, repeating the last one.
+func respondInTurn(handlers ...http.HandlerFunc) http.Handler {
+	var calls atomic.Int32
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1)) - 1
+		handlers[min(call, len(handlers)-1)](w, r)
+	})
+}
+
+func status(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		w.Write([]byte(`{"message":"failure"}`))
+	}
+}
+
+func pullRequest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"number":1,"head":{"ref":"fix-login"}}`))
+}
+
+func secondaryRateLimit(retryAfter string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", retryAfter)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"You have exceeded a secondary rate limit","documentation_url":"https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`))
+	}
+}
+
+var testPR = PullRequest{Repository: "acme/api", Number: 1}
+
+func TestRetrySucceedsAfterServerErrors(t *testing.T) {
+	server := httptest.NewServer(respondInTurn(status(http.StatusBadGateway), status(http.StatusBadGateway), pullRequest))
+	defer server.Close()
+	ops, transport := newTestOperations(t, server.URL, testRetryPolicy)
+
+	branch, err := ops.FetchHeadBranch(testPR)
+	if err != nil {
+		t.Fatalf("FetchHeadBranch() error = %v", err)
+	}
+	if branch != "fix-login" {
+		t.Errorf("FetchHeadBranch() = %q, want fix-login", branch)
+	}
+	if got := transport.requests.Load(); got != 3 {
+		t.Errorf("made %d requests, want 3", got)
+	}
+}
+
+func TestRetryRunsOutOfAttempts(t *testing.T) {
+	server := httptest.NewServer(status(http.StatusBadGateway))
+	defer server.Close()
+	ops, transport := newTestOperations(t, server.URL, testRetryPolicy)
+
+	_, err := ops.FetchHeadBranch(testPR)
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || serverErr.Status != http.StatusBadGateway {
+		t.Errorf("FetchHeadBranch() error = %v, want a 502 ServerError", err)
+	}
+	if got := transport.requests.Load(); got != 3 {
+		t.Errorf("made %d requests, want 3", got)
+	}
+}
+
+func TestRetrySkipsAuthErrors(t *testing.T) {
+	server := httptest.NewServer(status(http.StatusUnauthorized))
+	defer server.Close()
+	ops, transport := newTestOperations(t, server.URL, testRetryPolicy)
+
+	_, err := ops.FetchHeadBranch(testPR)
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Errorf("FetchHeadBranch() error = %v, want an AuthError", err)
+	}
+	if got := transport.requests.Load(); got != 1 {
+		t.Errorf("made %d requests, want 1", got)
+	}
+}
+
+func TestRetryConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	ops, transport := newTestOperations(t, "http://"+address, testRetryPolicy)
+
+	_, err = ops.FetchHeadBranch(testPR)
+	var networkErr *NetworkError
+	if !errors.As(err, &networkErr) {
+		t.Errorf("FetchHeadBranch() error = %v, want a NetworkError", err)
+	}
+	if got := transport.requests.Load(); got != 3 {
+		t.Errorf("made %d requests, want 3", got)
+	}
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	server := httptest.NewServer(respondInTurn(secondaryRateLimit("1"), pullRequest))
+	defer server.Close()
+	ops, transport := newTestOperations(t, server.URL, testRetryPolicy)
+
+	start := time.Now()
+	branch, err := ops.FetchHeadBranch(testPR)
+	if err != nil {
+		t.Fatalf("FetchHeadBranch() error = %v", err)
+	}
+	if branch != "fix-login" {
+		t.Errorf("FetchHeadBranch() = %q, want fix-login", branch)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want at least the 1s of Retry-After", elapsed)
+	}
+	if got := transport.requests.Load(); got != 2 {
+		t.Errorf("made %d requests, want 2", got)
+	}
+}
+
+func TestRetryGivesUpOnLongRateLimits(t *testing.T) {
+	server := httptest.NewServer(secondaryRateLimit("120"))
+	defer server.Close()
+	ops, transport := newTestOperations(t, server.URL, testRetryPolicy)
+
+	_, err := ops.FetchHeadBranch(testPR)
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Errorf("FetchHeadBranch() error = %v, want a RateLimitError", err)
+	}
+	if got := transport.requests.Load(); got != 1 {
+		t.Errorf("made %d requests, want 1", got)
+	}
+}
@@ -0,0 +1,200 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gh "github.com/google/go-github/v74/github"
+)
+
+type ReviewState string
+
+const (
+	ReviewStateUnknown          ReviewState = ""
+	ReviewStatePending          ReviewState = "pending"
+	ReviewStateCommented        ReviewState = "commented"
+	ReviewStateApproved         ReviewState = "approved"
+	ReviewStateChangesRequested ReviewState = "changes_requested"
+)
+
+type CIState string
+
+const (
+	CIStateUnknown CIState = ""
+	CIStatePending CIState = "pending"
+	CIStateSuccess CIState = "success"
+	CIStateFailure CIState = "failure"
+)
+
+// FetchStatus fills the head commit and branches, review state and CI state of the given PR.
+func (ops *GhOperations) FetchStatus(pr PullRequest) (PullRequest, error) {
+	owner, name, found := strings.Cut(pr.Repository, "/")
+	if !found {
+		return pr, fmt.Errorf("invalid repository %s, expected owner/name", pr.Repository)
+	}
+	client := ops.client
+	ctx := context.Background()
+	query := fmt.Sprintf("%s#%d", pr.Repository, pr.Number)
+	if pr.HeadSHA == "" {
+		var pull *gh.PullRequest
+		err := ops.retry(query, func() (err error) {
+			pull, _, err = client.PullRequests.Get(ctx, owner, name, pr.Number)
+			return err
+		})
+		if err != nil {
+			return pr, fmt.Errorf("failed to get pull request %s: %w", query, err)
+		}
+		pr.HeadSHA = pull.GetHead().GetSHA()
+		pr.HeadBranch = pull.GetHead().GetRef()
+		pr.BaseBranch = pull.GetBase().GetRef()
+	}
+
+	var reviews []*gh.PullRequestReview
+	err := ops.retry(query, func() (err error) {
+		reviews, _, err = client.PullRequests.ListReviews(ctx, owner, name, pr.Number, &gh.ListOptions{PerPage: 100})
+		return err
+	})
+	if err != nil {
+		return pr, fmt.Errorf("failed to list reviews of pull request %s: %w", query, err)
+	}
+	pr.ReviewState = reviewStateFromReviews(reviews)
+
+	var combinedStatus *gh.CombinedStatus
+	err = ops.retry(query, func() (err error) {
+		combinedStatus, _, err = client.Repositories.GetCombinedStatus(ctx, owner, name, pr.HeadSHA, &gh.ListOptions{PerPage: 100})
+		return err
+	})
+	if err != nil {
+		return pr, fmt.Errorf("failed to get commit status of pull request %s: %w", query, err)
+	}
+	var checkRuns *gh.ListCheckRunsResults
+	err = ops.retry(query, func() (err error) {
+		checkRuns, _, err = client.Checks.ListCheckRunsForRef(ctx, owner, name, pr.HeadSHA, &gh.ListCheckRunsOptions{ListOptions: gh.ListOptions{PerPage: 100}})
+		return err
+	})
+	if err != nil {
+		return pr, fmt.Errorf("failed to list check runs of pull request %s: %w", query, err)
+	}
+	pr.CIState = ciStateFromChecks(combinedStatus, checkRuns.CheckRuns)
+	return pr, nil
+}
+
+// FetchHeadBranch returns the head branch of the given PR, which search results do not include.
+func (ops *GhOperations) FetchHeadBranch(pr PullRequest) (string, error) {
+	if pr.HeadBranch != "" {
+		return pr.HeadBranch, nil
+	}
+	owner, name, found := strings.Cut(pr.Repository, "/")
+	if !found {
+		return "", fmt.Errorf("invalid repository %s, expected owner/name", pr.Repository)
+	}
+	query := fmt.Sprintf("%s#%d", pr.Repository, pr.Number)
+	var pull *gh.PullRequest
+	err := ops.retry(query, func() (err error) {
+		pull, _, err = ops.client.PullRequests.Get(context.Background(), owner, name, pr.Number)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get pull request %s: %w", query, err)
+	}
+	return pull.GetHead().GetRef(), nil
+}
+
+// FetchReviewRequestedAt fills the time of the latest review request of the given PR from its timeline, whoever it
+// was addressed to, leaving it zero when there was none.
+func (ops *GhOperations) FetchReviewRequestedAt(pr PullRequest) (PullRequest, error) {
+	owner, name, found := strings.Cut(pr.Repository, "/")
+	if !found {
+		return pr, fmt.Errorf("invalid repository %s, expected owner/name", pr.Repository)
+	}
+	ctx := context.Background()
+	query := fmt.Sprintf("%s#%d", pr.Repository, pr.Number)
+	options := gh.ListOptions{PerPage: 100}
+	for {
+		var events []*gh.Timeline
+		var response *gh.Response
+		err := ops.retry(query, func() (err error) {
+			events, response, err = ops.client.Issues.ListIssueTimeline(ctx, owner, name, pr.Number, &options)
+			return err
+		})
+		if err != nil {
+			return pr, fmt.Errorf("failed to list timeline of pull request %s: %w", query, err)
+		}
+		for _, event := range events {
+			if event.GetEvent() == "review_requested" && event.GetCreatedAt().After(pr.ReviewRequestedAt) {
+				pr.ReviewRequestedAt = event.GetCreatedAt().Time
+			}
+		}
+		if response.NextPage == 0 {
+			return pr, nil
+		}
+		options.Page = response.NextPage
+	}
+}
+
+// reviewStateFromReviews considers only the latest review of each reviewer, so a re-approval clears earlier change requests.
+func reviewStateFromReviews(reviews []*gh.PullRequestReview) ReviewState {
+	latestByReviewer := make(map[string]string)
+	for _, review := range reviews {
+		state := review.GetState()
+		if state == "PENDING" || state == "DISMISSED" {
+			continue
+		}
+		login := review.GetUser().GetLogin()
+		if state == "COMMENTED" && latestByReviewer[login] != "" {
+			continue
+		}
+		latestByReviewer[login] = state
+	}
+	result := ReviewStatePending
+	for _, state := range latestByReviewer {
+		switch state {
+		case "CHANGES_REQUESTED":
+			return ReviewStateChangesRequested
+		case "APPROVED":
+			result = ReviewStateApproved
+		case "COMMENTED":
+			if result == ReviewStatePending {
+				result = ReviewStateCommented
+			}
+		}
+	}
+	return result
+}
+
+func ciStateFromChecks(combinedStatus *gh.CombinedStatus, checkRuns []*gh.CheckRun) CIState {
+	states := make([]CIState, 0, len(checkRuns)+1)
+	if combinedStatus.GetTotalCount() > 0 {
+		switch combinedStatus.GetState() {
+		case "success":
+			states = append(states, CIStateSuccess)
+		case "pending":
+			states = append(states, CIStatePending)
+		default:
+			states = append(states, CIStateFailure)
+		}
+	}
+	for _, run := range checkRuns {
+		if run.GetStatus() != "completed" {
+			states = append(states, CIStatePending)
+			continue
+		}
+		switch run.GetConclusion() {
+		case "success", "neutral", "skipped":
+			states = append(states, CIStateSuccess)
+		default:
+			states = append(states, CIStateFailure)
+		}
+	}
+	result := CIStateUnknown
+	for _, state := range states {
+		if state == CIStateFailure {
+			return CIStateFailure
+		}
+		if state == CIStatePending || result == CIStateUnknown {
+			result = state
+		}
+	}
+	return result
+}
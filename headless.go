@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"macos-gh-bar/config"
+	"macos-gh-bar/core"
+	"macos-gh-bar/github"
+	"macos-gh-bar/state"
+	"macos-gh-bar/view"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+type headlessPR struct {
+	Category    string `json:"category"`
+	Hidden      bool   `json:"hidden"`
+	Repository  string `json:"repository"`
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	URL         string `json:"url"`
+	Draft       bool   `json:"draft"`
+	ReviewState string `json:"review_state,omitempty"`
+	CIState     string `json:"ci_state,omitempty"`
+	Staleness   string `json:"staleness,omitempty"`
+	Seen        bool   `json:"seen"`
+}
+
+// runHeadless fetches the PRs once and prints them, returning the process exit code. The local state is only read,
+// so listing never marks anything as seen or observed.
+func runHeadless(conf config.Configuration, store *state.Store, format string, out io.Writer) int {
+	ghops := github.NewGithubOperations(conf.ResolveGithubToken()).WithRetryPolicy(conf.GithubRetryPolicy())
+	prsModel, errs := core.FetchPRs(ghops, conf, stateHideRule(store))
+	menuModel := view.BuildMenuModel(prsModel, nil, conf, prActionState(store), time.Now())
+
+	var err error
+	switch format {
+	case "tree":
+		_, err = io.WriteString(out, menuModel.Text())
+	case "table":
+		err = writeHeadlessTable(out, headlessPRs(menuModel))
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(headlessPRs(menuModel))
+	default:
+		err = fmt.Errorf("unknown output format %s, expected tree, table or json", format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if fetchErr := errors.Join(errs...); fetchErr != nil {
+		fmt.Fprintln(os.Stderr, fetchErr)
+		return 1
+	}
+	return 0
+}
+
+func headlessPRs(model view.MenuModel) []headlessPR {
+	rows := make([]headlessPR, 0, model.PRCount)
+	appendSections := func(sections []view.MenuSection, hidden bool) {
+		for _, section := range sections {
+			// grouping by label lists a PR under each of its labels
+			listed := make(map[string]bool)
+			for _, subsection := range section.Subsections {
+				for _, item := range subsection.Items {
+					key := state.PRKey(item.PR.Repository, item.PR.Number)
+					if listed[key] {
+						continue
+					}
+					listed[key] = true
+					rows = append(rows, headlessPR{
+						Category:    section.Title,
+						Hidden:      hidden,
+						Repository:  item.PR.Repository,
+						Number:      item.PR.Number,
+						Title:       item.PR.Title,
+						Author:      item.PR.Author,
+						URL:         item.PR.URL,
+						Draft:       item.PR.Draft,
+						ReviewState: string(item.PR.ReviewState),
+						CIState:     string(item.PR.CIState),
+						Staleness:   string(item.Staleness),
+						Seen:        !item.Unseen,
+					})
+				}
+			}
+		}
+	}
+	appendSections(model.Sections, false)
+	appendSections(model.Hidden, true)
+	return rows
+}
+
+func writeHeadlessTable(out io.Writer, rows []headlessPR) error {
+	table := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "CATEGORY\tHIDDEN\tREPOSITORY\tNUMBER\tAUTHOR\tTITLE\tURL")
+	for _, row := range rows {
+		fmt.Fprintf(table, "%s\t%t\t%s\t%d\t%s\t%s\t%s\n", row.Category, row.Hidden, row.Repository, row.Number, row.Author, row.Title, row.URL)
+	}
+	return table.Flush()
+}
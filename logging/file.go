@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// DefaultDirectory is ~/Library/Logs/github-bar on macOS and the XDG state directory elsewhere.
+func DefaultDirectory(userHome string) string {
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(userHome, "Library", "Logs", "github-bar")
+	}
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(userHome, ".local", "state")
+	}
+	return filepath.Join(stateHome, "github-bar")
+}
+
+// RotatingFile appends to path and, once it would grow past maxSize bytes, shifts it to path.1, path.1 to path.2
+// and so on, deleting whatever goes beyond maxBackups.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	mutex      sync.Mutex
+	file       *os.File
+	size       int64
+}
+
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("error while creating log directory for %s: %w", path, err)
+	}
+	rotating := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := rotating.open(); err != nil {
+		return nil, err
+	}
+	return rotating, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("error while opening log file %s: %w", f.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error while reading log file %s: %w", f.path, err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("error while closing log file %s: %w", f.path, err)
+	}
+	os.Remove(fmt.Sprintf("%s.%d", f.path, f.maxBackups))
+	for backup := f.maxBackups - 1; backup >= 1; backup-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, backup), fmt.Sprintf("%s.%d", f.path, backup+1))
+	}
+	if f.maxBackups > 0 {
+		if err := os.Rename(f.path, f.path+".1"); err != nil {
+			return fmt.Errorf("error while rotating log file %s: %w", f.path, err)
+		}
+	} else if err := os.Remove(f.path); err != nil {
+		return fmt.Errorf("error while truncating log file %s: %w", f.path, err)
+	}
+	return f.open()
+}
+
+func (f *RotatingFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.file.Close()
+}
@@ -0,0 +1,164 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// readLogs reads path and its backups up to path.maxBackups+1, missing files read as "-".
+func readLogs(t *testing.T, path string, maxBackups int) []string {
+	t.Helper()
+	contents := make([]string, 0, maxBackups+2)
+	for backup := 0; backup <= maxBackups+1; backup++ {
+		name := path
+		if backup > 0 {
+			name = path + "." + strconv.Itoa(backup)
+		}
+		raw, err := os.ReadFile(name)
+		if os.IsNotExist(err) {
+			contents = append(contents, "-")
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(raw))
+	}
+	return contents
+}
+
+func TestRotatingFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   string
+		maxSize    int64
+		maxBackups int
+		writes     []string
+		// want holds the log file then its backups, one past maxBackups to check nothing is kept beyond
+		want []string
+	}{
+		{
+			name:       "below the size",
+			maxSize:    10,
+			maxBackups: 2,
+			writes:     []string{"aaa\n", "bbb\n"},
+			want:       []string{"aaa\nbbb\n", "-", "-", "-"},
+		},
+		{
+			name:       "exactly the size",
+			maxSize:    8,
+			maxBackups: 2,
+			writes:     []string{"aaa\n", "bbb\n"},
+			want:       []string{"aaa\nbbb\n", "-", "-", "-"},
+		},
+		{
+			name:       "past the size",
+			maxSize:    8,
+			maxBackups: 2,
+			writes:     []string{"aaa\n", "bbb\n", "ccc\n"},
+			want:       []string{"ccc\n", "aaa\nbbb\n", "-", "-"},
+		},
+		{
+			name:       "backups shifted and the oldest deleted",
+			maxSize:    4,
+			maxBackups: 2,
+			writes:     []string{"aaa\n", "bbb\n", "ccc\n", "ddd\n"},
+			want:       []string{"ddd\n", "ccc\n", "bbb\n", "-"},
+		},
+		{
+			name:       "a write larger than the size",
+			maxSize:    4,
+			maxBackups: 1,
+			writes:     []string{"aaaaaaaa\n", "bbbbbbbb\n"},
+			want:       []string{"bbbbbbbb\n", "aaaaaaaa\n", "-"},
+		},
+		{
+			name:       "no backups",
+			maxSize:    4,
+			maxBackups: 0,
+			writes:     []string{"aaa\n", "bbb\n"},
+			want:       []string{"bbb\n", "-"},
+		},
+		{
+			name:       "existing file counted",
+			existing:   "old\n",
+			maxSize:    6,
+			maxBackups: 1,
+			writes:     []string{"aaa\n"},
+			want:       []string{"aaa\n", "old\n", "-"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "logs", "github-bar.log")
+			if test.existing != "" {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(test.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			file, err := OpenRotatingFile(path, test.maxSize, test.maxBackups)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, write := range test.writes {
+				if n, err := file.Write([]byte(write)); err != nil || n != len(write) {
+					t.Fatalf("Write(%q) = %d, %v", write, n, err)
+				}
+			}
+			if err := file.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := readLogs(t, path, test.maxBackups); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("logs = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRotatingFileReopened(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "github-bar.log")
+	file, err := OpenRotatingFile(path, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, write := range []string{"aaa\n", "bbb\n", "ccc\n"} {
+		if _, err := file.Write([]byte(write)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the size of the rotated file carries over to the next run
+	reopened, err := OpenRotatingFile(path, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, write := range []string{"ddd\n", "eee\n"} {
+		if _, err := reopened.Write([]byte(write)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := reopened.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"eee\n", "ccc\nddd\n", "-"}
+	if got := readLogs(t, path, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("logs = %q, want %q", got, want)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 2 {
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("log directory holds %s, want the log file and one backup", strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"macos-gh-bar/native"
+	"path/filepath"
+	"sync"
+)
+
+const FileName = "github-bar.log"
+
+var level slog.LevelVar
+
+// Setup makes the default slog logger write through NSLog on macOS and to stderr elsewhere.
+func Setup() {
+	slog.SetDefault(slog.New(nsLogHandler()))
+}
+
+// EnableFile additionally writes JSON records to a rotating log file in directory.
+func EnableFile(directory string, maxSize int64, maxBackups int) error {
+	file, err := OpenRotatingFile(filepath.Join(directory, FileName), maxSize, maxBackups)
+	if err != nil {
+		return err
+	}
+	fileHandler := slog.NewJSONHandler(file, &slog.HandlerOptions{Level: &level})
+	slog.SetDefault(slog.New(fanoutHandler{nsLogHandler(), fileHandler}))
+	return nil
+}
+
+func SetLevel(newLevel slog.Level) {
+	level.Set(newLevel)
+}
+
+// nsLogHandler writes records without their time, as NSLog and the stderr fallback timestamp every line already.
+func nsLogHandler() slog.Handler {
+	return slog.NewTextHandler(&nsLogWriter{}, &slog.HandlerOptions{
+		Level: &level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	})
+}
+
+// nsLogWriter forwards each written line to native.NSLog.
+type nsLogWriter struct {
+	mutex sync.Mutex
+}
+
+func (w *nsLogWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		native.NSLog(string(line))
+	}
+	return len(p), nil
+}
+
+type fanoutHandler []slog.Handler
+
+func (handlers fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (handlers fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range handlers {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (handlers fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	withAttrs := make(fanoutHandler, 0, len(handlers))
+	for _, handler := range handlers {
+		withAttrs = append(withAttrs, handler.WithAttrs(attrs))
+	}
+	return withAttrs
+}
+
+func (handlers fanoutHandler) WithGroup(name string) slog.Handler {
+	withGroup := make(fanoutHandler, 0, len(handlers))
+	for _, handler := range handlers {
+		withGroup = append(withGroup, handler.WithGroup(name))
+	}
+	return withGroup
+}
@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"macos-gh-bar/slices"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const SummariesFileName = "refresh-summaries.json"
+
+type RefreshSummary struct {
+	Time       time.Time      `json:"time"`
+	DurationMS int64          `json:"duration_ms"`
+	Shown      map[string]int `json:"shown"`
+	Hidden     map[string]int `json:"hidden"`
+	Errors     []string       `json:"errors,omitempty"`
+}
+
+// SummaryLog keeps the last refresh summaries in a JSON file next to the logs.
+type SummaryLog struct {
+	path      string
+	mutex     sync.Mutex
+	summaries *slices.Ring[RefreshSummary]
+}
+
+// OpenSummaryLog loads the summaries kept by a previous run, if any.
+func OpenSummaryLog(directory string, capacity int) (*SummaryLog, error) {
+	summaryLog := &SummaryLog{path: filepath.Join(directory, SummariesFileName), summaries: slices.NewRing[RefreshSummary](capacity)}
+	raw, err := os.ReadFile(summaryLog.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return summaryLog, nil
+	}
+	if err != nil {
+		return summaryLog, fmt.Errorf("error while reading refresh summaries %s: %w", summaryLog.path, err)
+	}
+	var previous []RefreshSummary
+	if err := json.Unmarshal(raw, &previous); err != nil {
+		return summaryLog, fmt.Errorf("error while parsing refresh summaries %s: %w", summaryLog.path, err)
+	}
+	for _, summary := range previous {
+		summaryLog.summaries.Push(summary)
+	}
+	return summaryLog, nil
+}
+
+func (l *SummaryLog) Record(summary RefreshSummary) error {
+	slog.Info("Refresh summary", "duration", time.Duration(summary.DurationMS)*time.Millisecond,
+		"shown", summary.Shown, "hidden", summary.Hidden, "errors", len(summary.Errors))
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.summaries.Push(summary)
+	raw, err := json.MarshalIndent(l.summaries.Items(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error while encoding refresh summaries: %w", err)
+	}
+	if err := os.WriteFile(l.path, raw, 0o644); err != nil {
+		return fmt.Errorf("error while writing refresh summaries %s: %w", l.path, err)
+	}
+	return nil
+}
@@ -1,3 +1,5 @@
+//go:build darwin
+
 package native
 
 // #cgo CFLAGS: -x objective-c
@@ -9,8 +11,6 @@ package native
 // }
 import "C"
 import (
-	"fmt"
-
 	"github.com/progrium/darwinkit/macos/foundation"
 )
 
@@ -18,6 +18,3 @@ func NSLog(message string) {
 	foundationMsg := foundation.String_StringWithString(message)
 	C.GoNSLog(foundationMsg.Ptr())
 }
-func FNSLog(format string, args ...any) {
-	NSLog(fmt.Sprintf(format, args...))
-}
@@ -0,0 +1,11 @@
+//go:build !darwin
+
+package native
+
+import (
+	"log"
+)
+
+func NSLog(message string) {
+	log.Println(message)
+}
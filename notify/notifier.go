@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"fmt"
+	"log/slog"
+	"macos-gh-bar/config"
+	"macos-gh-bar/core"
+	"macos-gh-bar/github"
+)
+
+type Notification struct {
+	Title    string
+	Subtitle string
+	Body     string
+	URL      string
+}
+
+type Notifier interface {
+	Notify(notification Notification) error
+}
+
+type Kind string
+
+const (
+	KindAdded            Kind = "added"
+	KindRemoved          Kind = "removed"
+	KindNewCommits       Kind = "new_commits"
+	KindApproved         Kind = "approved"
+	KindChangesRequested Kind = "changes_requested"
+	KindCIFailed         Kind = "ci_failed"
+	KindCIPassed         Kind = "ci_passed"
+)
+
+// Dispatch notifies every event kind the configuration opted into for the categories involved.
+func Dispatch(notifier Notifier, conf config.Configuration, events []core.PREvent) {
+	for _, notification := range Notifications(conf, events) {
+		if err := notifier.Notify(notification); err != nil {
+			slog.Error("Error posting notification", "body", notification.Body, "error", err)
+		}
+	}
+}
+
+// Notifications converts events into the notifications opted in by the configuration, at most one per PR and kind.
+func Notifications(conf config.Configuration, events []core.PREvent) []Notification {
+	notifications := make([]Notification, 0)
+	notified := make(map[string]bool)
+	for _, event := range events {
+		for category, kinds := range eventKinds(event) {
+			for _, kind := range kinds {
+				key := fmt.Sprintf("%s#%d/%s", event.PR.Repository, event.PR.Number, kind)
+				if notified[key] || !conf.NotificationEnabled(category, string(kind)) {
+					continue
+				}
+				notified[key] = true
+				notifications = append(notifications, notificationFor(category, kind, event.PR))
+			}
+		}
+	}
+	return notifications
+}
+
+// eventKinds maps an event to the notification kinds it triggers per category. A PR entering a category,
+// like a new review request on a known PR, is notified as added.
+func eventKinds(event core.PREvent) map[string][]Kind {
+	kinds := make(map[string][]Kind)
+	switch event.Type {
+	case core.PRAdded:
+		for _, category := range event.Categories {
+			kinds[category] = append(kinds[category], KindAdded)
+		}
+	case core.PRRemoved:
+		for _, category := range event.PreviousCategories {
+			kinds[category] = append(kinds[category], KindRemoved)
+		}
+	case core.PRMovedCategory:
+		for _, category := range event.AddedCategories() {
+			kinds[category] = append(kinds[category], KindAdded)
+		}
+		for _, category := range event.RemovedCategories() {
+			kinds[category] = append(kinds[category], KindRemoved)
+		}
+	case core.PRStatusChanged:
+		statusKinds := statusChangeKinds(event.Previous, event.PR)
+		for _, category := range event.Categories {
+			kinds[category] = append(kinds[category], statusKinds...)
+		}
+	}
+	return kinds
+}
+
+func statusChangeKinds(previous, current github.PullRequest) []Kind {
+	kinds := make([]Kind, 0)
+	if previous.HeadSHA != "" && current.HeadSHA != "" && previous.HeadSHA != current.HeadSHA {
+		kinds = append(kinds, KindNewCommits)
+	}
+	if previous.ReviewState != github.ReviewStateUnknown && previous.ReviewState != current.ReviewState {
+		switch current.ReviewState {
+		case github.ReviewStateApproved:
+			kinds = append(kinds, KindApproved)
+		case github.ReviewStateChangesRequested:
+			kinds = append(kinds, KindChangesRequested)
+		}
+	}
+	if previous.CIState != github.CIStateUnknown && previous.CIState != current.CIState {
+		switch current.CIState {
+		case github.CIStateFailure:
+			kinds = append(kinds, KindCIFailed)
+		case github.CIStateSuccess:
+			kinds = append(kinds, KindCIPassed)
+		}
+	}
+	return kinds
+}
+
+func notificationFor(category string, kind Kind, pr github.PullRequest) Notification {
+	reference := fmt.Sprintf("%s#%d", pr.Repository, pr.Number)
+	var body string
+	switch kind {
+	case KindAdded:
+		body = fmt.Sprintf("@%s's %s is now in %s", pr.Author, reference, category)
+	case KindRemoved:
+		body = fmt.Sprintf("%s is no longer in %s", reference, category)
+	case KindNewCommits:
+		body = fmt.Sprintf("New commits pushed to %s", reference)
+	case KindApproved:
+		body = fmt.Sprintf("%s was approved", reference)
+	case KindChangesRequested:
+		body = fmt.Sprintf("Changes requested on %s", reference)
+	case KindCIFailed:
+		body = fmt.Sprintf("CI failed on %s", reference)
+	case KindCIPassed:
+		body = fmt.Sprintf("CI passed on %s", reference)
+	}
+	return Notification{
+		Title:    category,
+		Subtitle: pr.Title,
+		Body:     body,
+		URL:      pr.URL,
+	}
+}
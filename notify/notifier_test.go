@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"errors"
+	"macos-gh-bar/config"
+	"macos-gh-bar/core"
+	"macos-gh-bar/github"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// recordingNotifier keeps every notification it is asked to post, failing the ones whose body is in fail.
+type recordingNotifier struct {
+	posted []Notification
+	fail   map[string]bool
+}
+
+func (n *recordingNotifier) Notify(notification Notification) error {
+	n.posted = append(n.posted, notification)
+	if n.fail[notification.Body] {
+		return errors.New("notification center unavailable")
+	}
+	return nil
+}
+
+func bodies(notifications []Notification) []string {
+	result := make([]string, 0, len(notifications))
+	for _, notification := range notifications {
+		result = append(result, notification.Title+": "+notification.Body)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func testPR() github.PullRequest {
+	return github.PullRequest{Repository: "acme/api", Number: 7, Title: "Fix login", Author: "alice", URL: "https://github.com/acme/api/pull/7"}
+}
+
+func TestNotificationsOptInPerCategory(t *testing.T) {
+	conf := config.Configuration{Notifications: map[string][]string{"To Review": {"added"}}}
+	events := []core.PREvent{{Type: core.PRAdded, PR: testPR(), Categories: []string{"Created", "To Review"}}}
+
+	got := bodies(Notifications(conf, events))
+	want := []string{"To Review: @alice's acme/api#7 is now in To Review"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Notifications() = %q, want %q", got, want)
+	}
+}
+
+func TestNotificationsOncePerPRAndKind(t *testing.T) {
+	conf := config.Configuration{Notifications: map[string][]string{"To Review": {"added"}, "Created": {"added"}}}
+	events := []core.PREvent{
+		{Type: core.PRAdded, PR: testPR(), Categories: []string{"Created", "To Review"}},
+		{Type: core.PRMovedCategory, PR: testPR(), Categories: []string{"Created"}, PreviousCategories: []string{"Other"}},
+	}
+
+	if got := Notifications(conf, events); len(got) != 1 {
+		t.Errorf("Notifications() = %q, want a single added notification", bodies(got))
+	}
+}
+
+func TestNotificationsStatusKinds(t *testing.T) {
+	conf := config.Configuration{Notifications: map[string][]string{
+		"Created": {"new_commits", "approved", "changes_requested", "ci_failed", "ci_passed", "removed"},
+	}}
+	previous := testPR()
+	previous.HeadSHA = "aaa"
+	previous.ReviewState = github.ReviewStatePending
+	previous.CIState = github.CIStateSuccess
+	current := testPR()
+	current.HeadSHA = "bbb"
+	current.ReviewState = github.ReviewStateApproved
+	current.CIState = github.CIStateFailure
+	unknown := testPR()
+
+	tests := []struct {
+		name  string
+		event core.PREvent
+		want  []string
+	}{
+		{
+			name:  "every status changed",
+			event: core.PREvent{Type: core.PRStatusChanged, PR: current, Previous: previous, Categories: []string{"Created"}},
+			want: []string{
+				"Created: CI failed on acme/api#7",
+				"Created: New commits pushed to acme/api#7",
+				"Created: acme/api#7 was approved",
+			},
+		},
+		{
+			name:  "changes requested and CI passed",
+			event: core.PREvent{Type: core.PRStatusChanged, PR: withStates(testPR(), github.ReviewStateChangesRequested, github.CIStateSuccess), Previous: withStates(testPR(), github.ReviewStateApproved, github.CIStatePending), Categories: []string{"Created"}},
+			want: []string{
+				"Created: CI passed on acme/api#7",
+				"Created: Changes requested on acme/api#7",
+			},
+		},
+		{
+			name:  "previous state unknown",
+			event: core.PREvent{Type: core.PRStatusChanged, PR: current, Previous: unknown, Categories: []string{"Created"}},
+			want:  []string{},
+		},
+		{
+			name:  "removed",
+			event: core.PREvent{Type: core.PRRemoved, PR: testPR(), Previous: testPR(), PreviousCategories: []string{"Created"}},
+			want:  []string{"Created: acme/api#7 is no longer in Created"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := bodies(Notifications(conf, []core.PREvent{test.event}))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Notifications() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func withStates(pr github.PullRequest, review github.ReviewState, ci github.CIState) github.PullRequest {
+	pr.ReviewState = review
+	pr.CIState = ci
+	return pr
+}
+
+func TestDispatchPostsEveryNotification(t *testing.T) {
+	conf := config.Configuration{Notifications: map[string][]string{"To Review": {"added", "removed"}}}
+	other := testPR()
+	other.Number = 8
+	notifier := &recordingNotifier{fail: map[string]bool{"@alice's acme/api#7 is now in To Review": true}}
+
+	Dispatch(notifier, conf, []core.PREvent{
+		{Type: core.PRAdded, PR: testPR(), Categories: []string{"To Review"}},
+		{Type: core.PRRemoved, PR: other, Previous: other, PreviousCategories: []string{"To Review"}},
+	})
+
+	want := []string{
+		"To Review: @alice's acme/api#7 is now in To Review",
+		"To Review: acme/api#8 is no longer in To Review",
+	}
+	if got := bodies(notifier.posted); !reflect.DeepEqual(got, want) {
+		t.Errorf("posted %q, want %q", got, want)
+	}
+	if notifier.posted[0].URL != testPR().URL || notifier.posted[0].Subtitle != "Fix login" {
+		t.Errorf("posted %+v, want the PR URL and title", notifier.posted[0])
+	}
+}
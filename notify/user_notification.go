@@ -0,0 +1,24 @@
+//go:build darwin
+
+package notify
+
+import (
+	"github.com/progrium/darwinkit/dispatch"
+	"github.com/progrium/darwinkit/macos/foundation"
+	"github.com/progrium/darwinkit/objc"
+)
+
+// UserNotifier posts to the macOS notification center through NSUserNotificationCenter.
+type UserNotifier struct{}
+
+func (UserNotifier) Notify(notification Notification) error {
+	dispatch.MainQueue().DispatchAsync(func() {
+		userNotification := foundation.NewUserNotification()
+		objc.Call[objc.Void](userNotification, objc.Sel("setTitle:"), notification.Title)
+		objc.Call[objc.Void](userNotification, objc.Sel("setSubtitle:"), notification.Subtitle)
+		objc.Call[objc.Void](userNotification, objc.Sel("setInformativeText:"), notification.Body)
+		center := objc.Call[objc.Object](objc.GetClass("NSUserNotificationCenter"), objc.Sel("defaultUserNotificationCenter"))
+		objc.Call[objc.Void](center, objc.Sel("deliverNotification:"), userNotification)
+	})
+	return nil
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"macos-gh-bar/core"
+	"macos-gh-bar/github"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status is what the endpoints expose, as currently shown by the menu.
+type Status struct {
+	FetchedAt time.Time
+	Offline   bool
+	Title     string
+	Total     int
+	Unseen    int
+	Overdue   int
+	PRs       core.PRMenuModel
+}
+
+// Server answers on 127.0.0.1 only, and every request must carry the token as a bearer token or a token parameter.
+type Server struct {
+	token   string
+	status  func() Status
+	refresh func()
+}
+
+// Info is written next to the state so scripts can find the port and token of the running app.
+type Info struct {
+	URL   string `json:"url"`
+	Port  int    `json:"port"`
+	Token string `json:"token"`
+}
+
+type prJSON struct {
+	Repository  string    `json:"repository"`
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Author      string    `json:"author"`
+	Draft       bool      `json:"draft"`
+	Hidden      bool      `json:"hidden"`
+	Labels      []string  `json:"labels,omitempty"`
+	ReviewState string    `json:"review_state,omitempty"`
+	CIState     string    `json:"ci_state,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitzero"`
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`
+}
+
+type categoryJSON struct {
+	Count  int      `json:"count"`
+	Hidden int      `json:"hidden"`
+	PRs    []prJSON `json:"prs"`
+}
+
+type prsJSON struct {
+	FetchedAt  time.Time               `json:"fetched_at,omitzero"`
+	Offline    bool                    `json:"offline"`
+	Title      string                  `json:"title"`
+	Total      int                     `json:"total"`
+	Unseen     int                     `json:"unseen"`
+	Overdue    int                     `json:"overdue"`
+	Categories map[string]categoryJSON `json:"categories"`
+}
+
+type healthJSON struct {
+	Status    string    `json:"status"`
+	Offline   bool      `json:"offline"`
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
+	Errors    []string  `json:"errors,omitempty"`
+}
+
+func New(status func() Status, refresh func()) (*Server, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("error while generating server token: %w", err)
+	}
+	return &Server{token: hex.EncodeToString(token), status: status, refresh: refresh}, nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /prs", s.handlePRs)
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("POST /refresh", s.handleRefresh)
+	return s.authorize(mux)
+}
+
+// ListenAndServe serves on 127.0.0.1 at port, a random one when 0, and writes the Info to infoFile once listening.
+func (s *Server) ListenAndServe(port int, infoFile string) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return fmt.Errorf("error while listening on port %d: %w", port, err)
+	}
+	port = listener.Addr().(*net.TCPAddr).Port
+	info := Info{URL: fmt.Sprintf("http://127.0.0.1:%d", port), Port: port, Token: s.token}
+	if err := writeInfo(infoFile, info); err != nil {
+		listener.Close()
+		return err
+	}
+	slog.Info("Serving PR status", "url", info.URL, "info", infoFile)
+	server := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	return server.Serve(listener)
+}
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+			token = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handlePRs(w http.ResponseWriter, r *http.Request) {
+	status := s.status()
+	response := prsJSON{
+		FetchedAt:  status.FetchedAt,
+		Offline:    status.Offline,
+		Title:      status.Title,
+		Total:      status.Total,
+		Unseen:     status.Unseen,
+		Overdue:    status.Overdue,
+		Categories: make(map[string]categoryJSON),
+	}
+	for category, prs := range status.PRs.Shown {
+		response.Categories[category] = categoryJSON{Count: len(prs), PRs: toJSON(prs, false)}
+	}
+	for category, prs := range status.PRs.Hidden {
+		categoryPRs := response.Categories[category]
+		categoryPRs.Hidden = len(prs)
+		categoryPRs.PRs = append(categoryPRs.PRs, toJSON(prs, true)...)
+		response.Categories[category] = categoryPRs
+	}
+	if category := r.URL.Query().Get("category"); category != "" {
+		categoryPRs, found := response.Categories[category]
+		if !found {
+			http.Error(w, fmt.Sprintf("unknown category %s", category), http.StatusNotFound)
+			return
+		}
+		response.Categories = map[string]categoryJSON{category: categoryPRs}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	status := s.status()
+	response := healthJSON{Status: "ok", Offline: status.Offline, FetchedAt: status.FetchedAt}
+	if status.Offline {
+		response.Status = "offline"
+	} else if len(status.PRs.Errors) > 0 {
+		response.Status = "degraded"
+	}
+	for _, err := range status.PRs.Errors {
+		response.Errors = append(response.Errors, fmt.Sprintf("%s: %s", err.Query, github.UserMessage(err)))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleRefresh requests a refresh and answers right away, the new PRs show up in /prs once fetched. The refresh
+// callback coalesces concurrent and frequent requests.
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	go s.refresh()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func toJSON(prs []github.PullRequest, hidden bool) []prJSON {
+	converted := make([]prJSON, 0, len(prs))
+	for _, pr := range prs {
+		converted = append(converted, prJSON{
+			Repository:  pr.Repository,
+			Number:      pr.Number,
+			Title:       pr.Title,
+			URL:         pr.URL,
+			Author:      pr.Author,
+			Draft:       pr.Draft,
+			Hidden:      hidden,
+			Labels:      pr.Labels,
+			ReviewState: string(pr.ReviewState),
+			CIState:     string(pr.CIState),
+			CreatedAt:   pr.CreatedAt,
+			UpdatedAt:   pr.UpdatedAt,
+		})
+	}
+	sort.SliceStable(converted, func(i, j int) bool {
+		return converted[i].Repository < converted[j].Repository ||
+			converted[i].Repository == converted[j].Repository && converted[i].Number < converted[j].Number
+	})
+	return converted
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Warn("Error writing HTTP response", "error", err)
+	}
+}
+
+func writeInfo(path string, info Info) error {
+	raw, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error while encoding server info %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("error while creating directory for server info %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		return fmt.Errorf("error while writing server info %s: %w", path, err)
+	}
+	// WriteFile keeps the mode of an existing file, and the token must not be readable by other users
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("error while restricting server info %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"macos-gh-bar/core"
+	"macos-gh-bar/github"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testStatus = Status{
+	FetchedAt: time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC),
+	Title:     "1/2",
+	Total:     2,
+	Unseen:    1,
+	PRs: core.PRMenuModel{
+		Shown: map[string][]github.PullRequest{
+			"To Review": {{Repository: "acme/web", Number: 3}, {Repository: "acme/api", Number: 1}},
+		},
+		Hidden: map[string][]github.PullRequest{
+			"To Review": {{Repository: "acme/api", Number: 2}},
+		},
+	},
+}
+
+func newTestServer(t *testing.T, status Status) (*Server, *httptest.Server, *atomic.Int32) {
+	t.Helper()
+	refreshes := &atomic.Int32{}
+	s, err := New(func() Status { return status }, func() { refreshes.Add(1) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(s.Handler())
+	t.Cleanup(httpServer.Close)
+	return s, httpServer, refreshes
+}
+
+func request(t *testing.T, method string, url string, bearer string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { response.Body.Close() })
+	return response
+}
+
+func TestAuthorization(t *testing.T) {
+	s, httpServer, _ := newTestServer(t, testStatus)
+	tests := []struct {
+		name   string
+		path   string
+		bearer string
+		want   int
+	}{
+		{name: "bearer token", path: "/health", bearer: s.token, want: http.StatusOK},
+		{name: "token parameter", path: "/health?token=" + s.token, want: http.StatusOK},
+		{name: "no token", path: "/health", want: http.StatusUnauthorized},
+		{name: "empty token parameter", path: "/health?token=", want: http.StatusUnauthorized},
+		{name: "wrong bearer token", path: "/health", bearer: s.token[:len(s.token)-1] + "x", want: http.StatusUnauthorized},
+		{name: "token prefix", path: "/health?token=" + s.token[:8], want: http.StatusUnauthorized},
+		{name: "wrong bearer overrides a valid parameter", path: "/health?token=" + s.token, bearer: "wrong", want: http.StatusUnauthorized},
+		{name: "unknown path checked after the token", path: "/unknown", want: http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := request(t, http.MethodGet, httpServer.URL+test.path, test.bearer).StatusCode; got != test.want {
+				t.Errorf("GET %s = %d, want %d", test.path, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPRs(t *testing.T) {
+	s, httpServer, _ := newTestServer(t, testStatus)
+
+	response := request(t, http.MethodGet, httpServer.URL+"/prs", s.token)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("GET /prs = %d", response.StatusCode)
+	}
+	var body prsJSON
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Title != "1/2" || body.Total != 2 || body.Unseen != 1 || !body.FetchedAt.Equal(testStatus.FetchedAt) {
+		t.Errorf("GET /prs = %+v", body)
+	}
+	toReview := body.Categories["To Review"]
+	if toReview.Count != 2 || toReview.Hidden != 1 || len(toReview.PRs) != 3 {
+		t.Fatalf("To Review = %+v, want 2 shown and 1 hidden", toReview)
+	}
+	// shown PRs first, each part ordered by repository and number
+	want := []struct {
+		repository string
+		number     int
+		hidden     bool
+	}{{"acme/api", 1, false}, {"acme/web", 3, false}, {"acme/api", 2, true}}
+	for i, pr := range toReview.PRs {
+		if pr.Repository != want[i].repository || pr.Number != want[i].number || pr.Hidden != want[i].hidden {
+			t.Errorf("PR %d = %s#%d hidden %t, want %+v", i, pr.Repository, pr.Number, pr.Hidden, want[i])
+		}
+	}
+
+	if got := request(t, http.MethodGet, httpServer.URL+"/prs?category=To%20Review", s.token).StatusCode; got != http.StatusOK {
+		t.Errorf("GET /prs?category=To Review = %d, want 200", got)
+	}
+	if got := request(t, http.MethodGet, httpServer.URL+"/prs?category=Unknown", s.token).StatusCode; got != http.StatusNotFound {
+		t.Errorf("GET /prs?category=Unknown = %d, want 404", got)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	failed := testStatus
+	failed.PRs.Errors = []core.QueryError{{Category: "To Review", Query: "is:pr", Err: errors.New("boom")}}
+	offline := testStatus
+	offline.Offline = true
+	tests := []struct {
+		name   string
+		status Status
+		want   string
+		errors int
+	}{
+		{name: "ok", status: testStatus, want: "ok"},
+		{name: "failed query", status: failed, want: "degraded", errors: 1},
+		{name: "offline", status: offline, want: "offline"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, httpServer, _ := newTestServer(t, test.status)
+			response := request(t, http.MethodGet, httpServer.URL+"/health", s.token)
+			var body healthJSON
+			if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Status != test.want || len(body.Errors) != test.errors {
+				t.Errorf("GET /health = %+v, want status %s with %d errors", body, test.want, test.errors)
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	s, httpServer, refreshes := newTestServer(t, testStatus)
+
+	if got := request(t, http.MethodGet, httpServer.URL+"/refresh", s.token).StatusCode; got != http.StatusMethodNotAllowed {
+		t.Errorf("GET /refresh = %d, want 405", got)
+	}
+	if got := request(t, http.MethodPost, httpServer.URL+"/refresh", "").StatusCode; got != http.StatusUnauthorized {
+		t.Errorf("POST /refresh without token = %d, want 401", got)
+	}
+	if got := request(t, http.MethodPost, httpServer.URL+"/refresh", s.token).StatusCode; got != http.StatusAccepted {
+		t.Errorf("POST /refresh = %d, want 202", got)
+	}
+	deadline := time.Now().Add(time.Second)
+	for refreshes.Load() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := refreshes.Load(); got != 1 {
+		t.Errorf("refreshed %d times, want 1", got)
+	}
+}
+
+func TestWriteInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "server.json")
+	// a file left by an older version with a wider mode
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := Info{URL: "http://127.0.0.1:4242", Port: 4242, Token: "secret"}
+	if err := writeInfo(path, info); err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := stat.Mode().Perm(); mode != 0o600 {
+		t.Errorf("server info mode = %o, want 600", mode)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written Info
+	if err := json.Unmarshal(raw, &written); err != nil || written != info {
+		t.Errorf("server info = %+v, %v, want %+v", written, err, info)
+	}
+}
+
+func TestListenAndServe(t *testing.T) {
+	s, err := New(func() Status { return testStatus }, func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	infoFile := filepath.Join(t.TempDir(), "server.json")
+	go s.ListenAndServe(0, infoFile)
+
+	var info Info
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		raw, err := os.ReadFile(infoFile)
+		if err == nil && json.Unmarshal(raw, &info) == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server info not written: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if info.Port == 0 || info.Token != s.token {
+		t.Errorf("server info = %+v", info)
+	}
+	if got := request(t, http.MethodGet, info.URL+"/health", info.Token).StatusCode; got != http.StatusOK {
+		t.Errorf("GET /health = %d, want 200", got)
+	}
+}
@@ -6,12 +6,16 @@ import (
 
 func MapParallelMany[K comparable, V any, R any](items map[K]V, operation func(K, V) R) map[K]R {
 	var categoryWg sync.WaitGroup
+	var resultMutex sync.Mutex
 	var result = make(map[K]R, len(items))
 	for key, value := range items {
 		categoryWg.Add(1)
 		go func() {
 			defer categoryWg.Done()
-			result[key] = operation(key, value)
+			r := operation(key, value)
+			resultMutex.Lock()
+			defer resultMutex.Unlock()
+			result[key] = r
 		}()
 	}
 	categoryWg.Wait()
@@ -21,11 +25,14 @@ func MapParallelMany[K comparable, V any, R any](items map[K]V, operation func(K
 func ParallelMany[T any, R any](items []T, operation func(T) []R) []R {
 	result := make([]R, 0, len(items))
 	var queriesWg sync.WaitGroup
+	var resultMutex sync.Mutex
 	for _, item := range items {
 		queriesWg.Add(1)
 		go func() {
 			defer queriesWg.Done()
 			r := operation(item)
+			resultMutex.Lock()
+			defer resultMutex.Unlock()
 			result = append(result, r...)
 		}()
 	}
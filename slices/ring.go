@@ -0,0 +1,28 @@
+package slices
+
+// Ring keeps the last capacity items pushed to it. It is not safe for concurrent use.
+type Ring[T any] struct {
+	items    []T
+	next     int
+	capacity int
+}
+
+func NewRing[T any](capacity int) *Ring[T] {
+	return &Ring[T]{items: make([]T, 0, capacity), capacity: capacity}
+}
+
+func (r *Ring[T]) Push(item T) {
+	if len(r.items) < r.capacity {
+		r.items = append(r.items, item)
+		return
+	}
+	r.items[r.next] = item
+	r.next = (r.next + 1) % r.capacity
+}
+
+// Items returns the kept items, oldest first.
+func (r *Ring[T]) Items() []T {
+	items := make([]T, 0, len(r.items))
+	items = append(items, r.items[r.next:]...)
+	return append(items, r.items[:r.next]...)
+}
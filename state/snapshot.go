@@ -0,0 +1,40 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"macos-gh-bar/github"
+	"os"
+	"time"
+)
+
+// Snapshot is the PRs of every category as of the last fully successful fetch, shown at startup and while offline.
+type Snapshot struct {
+	Time time.Time                       `json:"time"`
+	PRs  map[string][]github.PullRequest `json:"prs"`
+}
+
+// LoadSnapshot returns false when no snapshot was saved yet.
+func LoadSnapshot(path string) (Snapshot, bool, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, false, nil
+	}
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("error while reading snapshot file %s: %w", path, err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return Snapshot{}, false, fmt.Errorf("error while parsing snapshot file %s: %w", path, err)
+	}
+	return snapshot, true, nil
+}
+
+func SaveSnapshot(path string, snapshot Snapshot) error {
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("error while encoding snapshot file %s: %w", path, err)
+	}
+	return writeFileAtomic(path, raw)
+}
@@ -0,0 +1,334 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"macos-gh-bar/github"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// recordRetention is how long a PR that is no longer returned by any query is remembered.
+const recordRetention = 30 * 24 * time.Hour
+
+type PRRecord struct {
+	Repository        string             `json:"repository"`
+	Number            int                `json:"number"`
+	Title             string             `json:"title"`
+	URL               string             `json:"url"`
+	Categories        []string           `json:"categories"`
+	FirstSeen         time.Time          `json:"first_seen"`
+	LastSeen          time.Time          `json:"last_seen"`
+	HeadSHA           string             `json:"head_sha,omitempty"`
+	ReviewState       github.ReviewState `json:"review_state,omitempty"`
+	CIState           github.CIState     `json:"ci_state,omitempty"`
+	UpdatedAt         time.Time          `json:"updated_at,omitzero"`
+	ReviewRequestedAt time.Time          `json:"review_requested_at,omitzero"`
+	SeenAt            *time.Time         `json:"seen_at,omitempty"`
+	SeenHeadSHA       string             `json:"seen_head_sha,omitempty"`
+	Snooze            *Snooze            `json:"snooze,omitempty"`
+}
+
+// Snooze hides a PR until a point in time, until its head commit changes, or until whichever comes first.
+type Snooze struct {
+	Until     *time.Time `json:"until,omitempty"`
+	UntilPush bool       `json:"until_push,omitempty"`
+	HeadSHA   string     `json:"head_sha,omitempty"`
+}
+
+func (snooze Snooze) Active(pr github.PullRequest, now time.Time) bool {
+	if snooze.Until != nil && !now.Before(*snooze.Until) {
+		return false
+	}
+	if snooze.UntilPush && pr.HeadSHA != "" && pr.HeadSHA != snooze.HeadSHA {
+		return false
+	}
+	return true
+}
+
+func (snooze Snooze) String() string {
+	switch {
+	case snooze.Until != nil && snooze.UntilPush:
+		return fmt.Sprintf("until next push or %s", snooze.Until.Format("Mon 15:04"))
+	case snooze.Until != nil:
+		return fmt.Sprintf("until %s", snooze.Until.Format("Mon 15:04"))
+	default:
+		return "until next push"
+	}
+}
+
+type storeFile struct {
+	PRs                map[string]PRRecord `json:"prs"`
+	HiddenPRs          []string            `json:"hidden_prs,omitempty"`
+	HiddenRepositories []string            `json:"hidden_repositories,omitempty"`
+}
+
+type Store struct {
+	path  string
+	mutex sync.Mutex
+	// saveMutex orders saves, so an older state never replaces a newer one.
+	saveMutex sync.Mutex
+	data      storeFile
+}
+
+func PRKey(repository string, number int) string {
+	return fmt.Sprintf("%s#%d", repository, number)
+}
+
+func DefaultDirectory(userHome string) string {
+	return filepath.Join(userHome, ".config", "github-bar", "state")
+}
+
+// LoadStore reads the store at path, starting empty when the file does not exist yet.
+func LoadStore(path string) (*Store, error) {
+	store := &Store{path: path, data: storeFile{PRs: make(map[string]PRRecord)}}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return store, fmt.Errorf("error while reading state file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(raw, &store.data); err != nil {
+		return store, fmt.Errorf("error while parsing state file %s: %w", path, err)
+	}
+	if store.data.PRs == nil {
+		store.data.PRs = make(map[string]PRRecord)
+	}
+	return store, nil
+}
+
+// Observe records every PR of the given categories as present at now and forgets PRs unseen for longer than the retention.
+func (s *Store) Observe(categoryPRs map[string][]github.PullRequest, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	observed := make(map[string]PRRecord)
+	for category, prs := range categoryPRs {
+		for _, pr := range prs {
+			key := PRKey(pr.Repository, pr.Number)
+			record, found := observed[key]
+			if !found {
+				record, found = s.data.PRs[key]
+				if !found {
+					record = PRRecord{Repository: pr.Repository, Number: pr.Number, FirstSeen: now}
+				}
+				record.Categories = nil
+			}
+			record.Title = pr.Title
+			record.URL = pr.URL
+			record.LastSeen = now
+			if pr.HeadSHA != "" {
+				record.HeadSHA = pr.HeadSHA
+			}
+			if pr.ReviewState != github.ReviewStateUnknown {
+				record.ReviewState = pr.ReviewState
+			}
+			if pr.CIState != github.CIStateUnknown {
+				record.CIState = pr.CIState
+			}
+			if pr.UpdatedAt.After(record.UpdatedAt) {
+				record.UpdatedAt = pr.UpdatedAt
+			}
+			if pr.ReviewRequestedAt.After(record.ReviewRequestedAt) {
+				record.ReviewRequestedAt = pr.ReviewRequestedAt
+			}
+			record.Categories = append(record.Categories, category)
+			observed[key] = record
+		}
+	}
+	for key, record := range observed {
+		sort.Strings(record.Categories)
+		if previous, found := s.data.PRs[key]; found && record.SeenAt != nil && seenInvalidated(previous, record) {
+			record.SeenAt = nil
+			record.SeenHeadSHA = ""
+		}
+		if record.Snooze != nil && !record.Snooze.Active(github.PullRequest{HeadSHA: record.HeadSHA}, now) {
+			record.Snooze = nil
+		}
+		s.data.PRs[key] = record
+	}
+	for key, record := range s.data.PRs {
+		if now.Sub(record.LastSeen) > recordRetention {
+			delete(s.data.PRs, key)
+		}
+	}
+}
+
+// seenInvalidated tells whether a seen PR got new commits, a new review request or entered a new category. Without
+// fetch_pr_status the head commit is unknown, so any update of the PR after it was seen counts instead, own comments
+// and reviews included. Review request times are only known for categories with an SLA using them.
+func seenInvalidated(previous, current PRRecord) bool {
+	if current.HeadSHA != "" && current.SeenHeadSHA != "" {
+		if current.HeadSHA != current.SeenHeadSHA {
+			return true
+		}
+	} else if current.UpdatedAt.After(*current.SeenAt) {
+		return true
+	}
+	if current.ReviewRequestedAt.After(*current.SeenAt) {
+		return true
+	}
+	for _, category := range current.Categories {
+		if !containsString(previous.Categories, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString expects items sorted, as record categories and hidden lists always are.
+func containsString(items []string, item string) bool {
+	index := sort.SearchStrings(items, item)
+	return index < len(items) && items[index] == item
+}
+
+func (s *Store) MarkSeen(repository string, number int, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := PRKey(repository, number)
+	record, found := s.data.PRs[key]
+	if !found {
+		return
+	}
+	record.SeenAt = &now
+	record.SeenHeadSHA = record.HeadSHA
+	s.data.PRs[key] = record
+}
+
+func (s *Store) Seen(repository string, number int) bool {
+	record, found := s.Get(repository, number)
+	return found && record.SeenAt != nil
+}
+
+// Snooze hides the PR until the given time, or until its next push when until is nil.
+func (s *Store) Snooze(pr github.PullRequest, until *time.Time, untilPush bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := PRKey(pr.Repository, pr.Number)
+	record, found := s.data.PRs[key]
+	if !found {
+		return
+	}
+	headSHA := pr.HeadSHA
+	if headSHA == "" {
+		headSHA = record.HeadSHA
+	}
+	record.Snooze = &Snooze{Until: until, UntilPush: untilPush, HeadSHA: headSHA}
+	s.data.PRs[key] = record
+}
+
+func (s *Store) Unsnooze(repository string, number int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := PRKey(repository, number)
+	record, found := s.data.PRs[key]
+	if !found {
+		return
+	}
+	record.Snooze = nil
+	s.data.PRs[key] = record
+}
+
+// ActiveSnooze returns the snooze hiding the PR at now, if any.
+func (s *Store) ActiveSnooze(pr github.PullRequest, now time.Time) (Snooze, bool) {
+	record, found := s.Get(pr.Repository, pr.Number)
+	if !found || record.Snooze == nil || !record.Snooze.Active(pr, now) {
+		return Snooze{}, false
+	}
+	return *record.Snooze, true
+}
+
+func (s *Store) HidePR(repository string, number int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data.HiddenPRs = addSorted(s.data.HiddenPRs, PRKey(repository, number))
+}
+
+func (s *Store) UnhidePR(repository string, number int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data.HiddenPRs = removeSorted(s.data.HiddenPRs, PRKey(repository, number))
+}
+
+func (s *Store) HideRepository(repository string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data.HiddenRepositories = addSorted(s.data.HiddenRepositories, repository)
+}
+
+func (s *Store) UnhideRepository(repository string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data.HiddenRepositories = removeSorted(s.data.HiddenRepositories, repository)
+}
+
+// Hidden tells whether the PR itself or its repository were hidden from the menu.
+func (s *Store) Hidden(repository string, number int) (hiddenPR bool, hiddenRepository bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return containsString(s.data.HiddenPRs, PRKey(repository, number)), containsString(s.data.HiddenRepositories, repository)
+}
+
+func addSorted(items []string, item string) []string {
+	if containsString(items, item) {
+		return items
+	}
+	items = append(items, item)
+	sort.Strings(items)
+	return items
+}
+
+func removeSorted(items []string, item string) []string {
+	index := sort.SearchStrings(items, item)
+	if index < len(items) && items[index] == item {
+		return append(items[:index], items[index+1:]...)
+	}
+	return items
+}
+
+func (s *Store) Get(repository string, number int) (PRRecord, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	record, found := s.data.PRs[PRKey(repository, number)]
+	return record, found
+}
+
+func (s *Store) Save() error {
+	s.saveMutex.Lock()
+	defer s.saveMutex.Unlock()
+	s.mutex.Lock()
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	s.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("error while encoding state file %s: %w", s.path, err)
+	}
+	return writeFileAtomic(s.path, raw)
+}
+
+// writeFileAtomic writes through a temporary file renamed over path, so a crash never leaves a truncated file. Each
+// write has its own temporary file, so concurrent writes never rename each other's.
+func writeFileAtomic(path string, raw []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("error while creating state directory for %s: %w", path, err)
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error while creating temporary state file for %s: %w", path, err)
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.Write(raw)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error while writing state file %s: %w", tmpFile.Name(), err)
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("error while replacing state file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package state
+
+import (
+	"macos-gh-bar/github"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestObserveResetsSeen(t *testing.T) {
+	seenAt := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	before := seenAt.Add(-time.Hour)
+	after := seenAt.Add(time.Hour)
+	base := github.PullRequest{Repository: "acme/api", Number: 1, UpdatedAt: before}
+
+	tests := []struct {
+		name       string
+		seen       github.PullRequest
+		next       github.PullRequest
+		categories []string
+		wantSeen   bool
+	}{
+		{name: "unchanged", seen: base, next: base, wantSeen: true},
+		{name: "new commit", seen: withHead(base, "aaa"), next: withHead(withUpdate(base, after), "bbb"), wantSeen: false},
+		{name: "updated with the same known head", seen: withHead(base, "aaa"), next: withHead(withUpdate(base, after), "aaa"), wantSeen: true},
+		{name: "updated without known head", seen: base, next: withUpdate(base, after), wantSeen: false},
+		{name: "review requested again", seen: withHead(base, "aaa"), next: withReviewRequest(withHead(base, "aaa"), after), wantSeen: false},
+		{name: "entered a new category", seen: base, next: base, categories: []string{"Created", "To Review"}, wantSeen: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store, err := LoadStore(filepath.Join(t.TempDir(), "prs.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			store.Observe(map[string][]github.PullRequest{"Created": {test.seen}}, before)
+			store.MarkSeen("acme/api", 1, seenAt)
+
+			next := map[string][]github.PullRequest{}
+			categories := test.categories
+			if categories == nil {
+				categories = []string{"Created"}
+			}
+			for _, category := range categories {
+				next[category] = []github.PullRequest{test.next}
+			}
+			store.Observe(next, after.Add(time.Minute))
+			if got := store.Seen("acme/api", 1); got != test.wantSeen {
+				t.Errorf("Seen() = %v, want %v", got, test.wantSeen)
+			}
+		})
+	}
+}
+
+func withHead(pr github.PullRequest, sha string) github.PullRequest {
+	pr.HeadSHA = sha
+	return pr
+}
+
+func withUpdate(pr github.PullRequest, updatedAt time.Time) github.PullRequest {
+	pr.UpdatedAt = updatedAt
+	return pr
+}
+
+func withReviewRequest(pr github.PullRequest, requestedAt time.Time) github.PullRequest {
+	pr.ReviewRequestedAt = requestedAt
+	return pr
+}
+
+func TestConcurrentSaves(t *testing.T) {
+	directory := t.TempDir()
+	store, err := LoadStore(filepath.Join(directory, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshotPath := filepath.Join(directory, "snapshot.json")
+	errs := make(chan error, 20)
+	for i := range 10 {
+		go func() {
+			store.HidePR("acme/api", i)
+			errs <- store.Save()
+		}()
+		go func() {
+			errs <- SaveSnapshot(snapshotPath, Snapshot{Time: time.Unix(int64(i), 0)})
+		}()
+	}
+	for range 20 {
+		if err := <-errs; err != nil {
+			t.Errorf("concurrent save: %v", err)
+		}
+	}
+
+	reloaded, err := LoadStore(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 10 {
+		if hiddenPR, _ := reloaded.Hidden("acme/api", i); !hiddenPR {
+			t.Errorf("acme/api#%d not hidden after the last save", i)
+		}
+	}
+	if _, found, err := LoadSnapshot(snapshotPath); !found || err != nil {
+		t.Errorf("LoadSnapshot() = %t, %v", found, err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(directory, "*.tmp")); len(leftovers) > 0 {
+		t.Errorf("temporary files left behind: %q", leftovers)
+	}
+}
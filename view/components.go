@@ -1,10 +1,11 @@
+//go:build darwin
+
 package view
 
 import (
 	"encoding/base64"
 	"errors"
-	"fmt"
-	"macos-gh-bar/native"
+	"log/slog"
 	"strings"
 
 	"github.com/progrium/darwinkit/dispatch"
@@ -62,6 +63,14 @@ func MenuItemNoAction(title string, charCode string) appkit.MenuItem {
 	return item
 }
 
+func MenuItemDisabled(title string) appkit.MenuItem {
+	item := appkit.NewMenuItem()
+	item.SetTitle(title)
+	item.SetEnabled(false)
+	objc.Retain(&item)
+	return item
+}
+
 func MenuItem(title string, charCode string, handler action.Handler) appkit.MenuItem {
 	item := appkit.NewMenuItemWithAction(title, charCode, handler)
 	objc.Retain(&item)
@@ -99,35 +108,18 @@ func DispatchAlert(title, message string) {
 
 func DispatchAlertOnError(err error) bool {
 	if err != nil {
-		native.NSLog(joinErrorMessages(err, " "))
+		slog.Error(joinErrorMessages(err, " "))
 		DispatchErrorAlert(err)
 		return true
 	}
 	return false
 }
 
-func DispatchMarkBarButtonOnError(statusItem appkit.StatusItem, err error) {
-	dispatch.MainQueue().DispatchAsync(func() {
-		currentTitle := statusItem.Button().Title()
-		if !strings.HasSuffix(currentTitle, "❗") {
-			statusItem.Button().SetTitle(currentTitle + "❗")
-		}
-		menuItems := statusItem.Menu().ItemArray()
-		lastItem := menuItems[len(menuItems)-1]
-		if lastItem.Title() == "Show last error" {
-			statusItem.Menu().RemoveItem(lastItem)
-		}
-		statusItem.Menu().AddItem(MenuItem("Show last error", "e", func(sender objc.Object) {
-			DispatchErrorAlert(err)
-		}))
-	})
-}
-
 func AppkitImageFromBase64(base64String string) appkit.Image {
 	// Decode base64 string to byte slice
 	data, err := base64.StdEncoding.DecodeString(base64String)
 	if err != nil {
-		native.NSLog(fmt.Sprintf("Error decoding base64: %v", err))
+		slog.Error("Error decoding base64", "error", err)
 		panic(err)
 	}
 	image := appkit.NewImageWithData(data)
@@ -144,3 +136,21 @@ func NewMenuWithTitle(title string) appkit.Menu {
 	objc.Retain(&menu)
 	return menu
 }
+
+// SetMenuItemTitle sets the title with the regular or bold menu font.
+func SetMenuItemTitle(item appkit.MenuItem, title string, bold bool) {
+	font := appkit.FontClass.MenuFontOfSize(0)
+	if bold {
+		font = appkit.FontClass.BoldSystemFontOfSize(font.PointSize())
+	}
+	attributedTitle := foundation.NewAttributedStringWithStringAttributes(title, map[foundation.AttributedStringKey]objc.IObject{
+		"NSFont": font,
+	})
+	item.SetAttributedTitle(attributedTitle)
+}
+
+func CopyToClipboard(text string) {
+	pasteboard := appkit.Pasteboard_GeneralPasteboard()
+	pasteboard.ClearContents()
+	pasteboard.SetStringForType(text, appkit.PasteboardTypeString)
+}
@@ -0,0 +1,43 @@
+//go:build darwin
+
+package view
+
+import (
+	"log/slog"
+	"macos-gh-bar/config"
+	"strings"
+
+	"github.com/progrium/darwinkit/macos/appkit"
+	"github.com/progrium/darwinkit/objc"
+)
+
+// RegisterGlobalHotkey runs handler whenever the hotkey is pressed in any application. macOS only delivers these key
+// events once the app is trusted for accessibility in the privacy settings.
+func RegisterGlobalHotkey(hotkey config.Hotkey, handler func()) {
+	var modifiers appkit.EventModifierFlags
+	if hotkey.Command {
+		modifiers |= appkit.EventModifierFlagCommand
+	}
+	if hotkey.Shift {
+		modifiers |= appkit.EventModifierFlagShift
+	}
+	if hotkey.Option {
+		modifiers |= appkit.EventModifierFlagOption
+	}
+	if hotkey.Control {
+		modifiers |= appkit.EventModifierFlagControl
+	}
+	relevant := appkit.EventModifierFlagCommand | appkit.EventModifierFlagShift | appkit.EventModifierFlagOption | appkit.EventModifierFlagControl
+	monitor := appkit.Event_AddGlobalMonitorForEventsMatchingMaskHandler(appkit.EventMaskKeyDown, func(event appkit.Event) {
+		flags := objc.Call[appkit.EventModifierFlags](event, objc.Sel("modifierFlags"))
+		if flags&relevant != modifiers {
+			return
+		}
+		if strings.ToLower(event.CharactersIgnoringModifiers()) != hotkey.Key {
+			return
+		}
+		handler()
+	})
+	objc.Retain(&monitor)
+	slog.Info("Registered global hotkey", "key", hotkey.Key, "modifiers", modifiers)
+}
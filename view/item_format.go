@@ -0,0 +1,122 @@
+package view
+
+import (
+	"fmt"
+	"log/slog"
+	"macos-gh-bar/config"
+	"macos-gh-bar/github"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// tooltipExcerptLength is how many characters of the PR description the tooltip shows.
+const tooltipExcerptLength = 280
+
+var ciGlyphs = map[github.CIState]string{
+	github.CIStateFailure: "❌",
+	github.CIStatePending: "⏳",
+	github.CIStateSuccess: "✅",
+}
+
+var reviewGlyphs = map[github.ReviewState]string{
+	github.ReviewStateChangesRequested: "✋",
+	github.ReviewStatePending:          "👀",
+	github.ReviewStateCommented:        "💬",
+	github.ReviewStateApproved:         "👍",
+}
+
+// ItemFormatData is what item_format is evaluated against.
+type ItemFormatData struct {
+	Title      string
+	Number     int
+	Author     string
+	Repository string
+	// RepoName is the repository without its owner.
+	RepoName    string
+	Age         string
+	Labels      string
+	Draft       bool
+	CIGlyph     string
+	ReviewGlyph string
+}
+
+func newItemFormatData(pr github.PullRequest, now time.Time) ItemFormatData {
+	_, repoName, _ := strings.Cut(pr.Repository, "/")
+	return ItemFormatData{
+		Title:       pr.Title,
+		Number:      pr.Number,
+		Author:      pr.Author,
+		Repository:  pr.Repository,
+		RepoName:    repoName,
+		Age:         formatAge(pr.CreatedAt, now),
+		Labels:      strings.Join(pr.Labels, ", "),
+		Draft:       pr.Draft,
+		CIGlyph:     ciGlyphs[pr.CIState],
+		ReviewGlyph: reviewGlyphs[pr.ReviewState],
+	}
+}
+
+// itemFormat is the item_format of a category parsed once for all its PRs.
+type itemFormat struct {
+	tmpl     *template.Template
+	maxWidth int
+}
+
+// newItemFormat falls back to the default format when item_format does not parse.
+func newItemFormat(category string, group config.QueryGroup) itemFormat {
+	tmpl, err := group.ItemTemplate()
+	if err != nil {
+		slog.Warn("Error parsing item_format, using the default format", "category", category, "error", err)
+	}
+	return itemFormat{tmpl: tmpl, maxWidth: group.ItemMaxWidth}
+}
+
+// title evaluates the item format, or the default format on errors, truncated to its item_max_width.
+func (f itemFormat) title(pr github.PullRequest, withRepository bool, now time.Time) string {
+	title := fmt.Sprintf("%s [#%d]", pr.Title, pr.Number)
+	if withRepository {
+		title = fmt.Sprintf("%s [%s#%d]", pr.Title, pr.Repository, pr.Number)
+	}
+	if f.tmpl != nil {
+		var formatted strings.Builder
+		if err := f.tmpl.Execute(&formatted, newItemFormatData(pr, now)); err == nil {
+			title = strings.TrimSpace(formatted.String())
+		} else {
+			slog.Warn("Error evaluating item_format, using the default format", "repository", pr.Repository, "number", pr.Number, "error", err)
+		}
+	}
+	return truncate(title, f.maxWidth)
+}
+
+// truncate shortens text to width characters, the last one an ellipsis, a width of 0 or less keeps it whole.
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if width <= 0 || len(runes) <= width {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:max(width-1, 0)])) + "…"
+}
+
+// itemTooltip is the full title followed by the start of the description.
+func itemTooltip(pr github.PullRequest) string {
+	body := strings.Join(strings.Fields(pr.Body), " ")
+	if body == "" {
+		return pr.Title
+	}
+	return pr.Title + "\n\n" + truncate(body, tooltipExcerptLength)
+}
+
+func formatAge(created time.Time, now time.Time) string {
+	if created.IsZero() {
+		return ""
+	}
+	age := now.Sub(created)
+	switch {
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(age.Hours()/24))
+}
@@ -0,0 +1,399 @@
+package view
+
+import (
+	"fmt"
+	"log/slog"
+	"macos-gh-bar/config"
+	"macos-gh-bar/core"
+	"macos-gh-bar/fuzzy"
+	"macos-gh-bar/github"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type MenuCommandKind string
+
+const (
+	CommandMarkAllSeen MenuCommandKind = "mark_all_seen"
+	CommandRefresh     MenuCommandKind = "refresh"
+	CommandOpenLogs    MenuCommandKind = "open_logs"
+	CommandQuit        MenuCommandKind = "quit"
+)
+
+type MenuCommand struct {
+	Kind  MenuCommandKind
+	Title string
+	Key   string
+}
+
+// MenuModel is everything the status menu shows, decided without AppKit so it can be rendered as text as well.
+type MenuModel struct {
+	Title string
+	// Notice is shown above the categories when set, e.g. while offline.
+	Notice   string
+	Sections []MenuSection
+	// Hidden lists the hidden and snoozed PRs in a submenu, with HiddenCount distinct PRs, rendered when not empty.
+	Hidden      []MenuSection
+	HiddenCount int
+	Commands    []MenuCommand
+	// Errors are the recent query errors, most recent first.
+	Errors []MenuMessage
+	// PRCount and Unseen count the distinct shown PRs of the categories contributing to the badge, Dot tells a
+	// contributing category with the dot badge_style has PRs.
+	PRCount int
+	Unseen  int
+	Dot     bool
+	// Overdue counts the distinct shown PRs past the SLA of their category.
+	Overdue int
+}
+
+// MenuMessage is an informational entry whose detail is shown on click.
+type MenuMessage struct {
+	Title  string
+	Detail string
+}
+
+// MenuSection is a category, with its PRs grouped in subsections.
+type MenuSection struct {
+	Title       string
+	Badge       string
+	Warnings    []MenuMessage
+	Subsections []MenuSubsection
+}
+
+// quickOpenKeys are the ⌘ keys opening the first PRs of the menu.
+var quickOpenKeys = []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}
+
+// PRs lists the distinct PRs of the section in menu order.
+func (section MenuSection) PRs() []github.PullRequest {
+	return section.MatchingPRs("")
+}
+
+// MatchingPRs lists the distinct PRs of the section matching the search query in menu order, all of them for an empty
+// query.
+func (section MenuSection) MatchingPRs(query string) []github.PullRequest {
+	listed := make(map[string]bool)
+	prs := make([]github.PullRequest, 0)
+	for _, subsection := range section.Subsections {
+		for _, item := range subsection.Items {
+			key := fmt.Sprintf("%s#%d", item.PR.Repository, item.PR.Number)
+			if !listed[key] && item.MatchesSearch(query) {
+				listed[key] = true
+				prs = append(prs, item.PR)
+			}
+		}
+	}
+	return prs
+}
+
+// MenuSubsection is a group of PRs along the group_by dimension of the category, its title is empty when ungrouped.
+type MenuSubsection struct {
+	Title string
+	Items []MenuPRItem
+}
+
+type MenuPRItem struct {
+	PR        github.PullRequest
+	Title     string
+	Badge     string
+	Bold      bool
+	Unseen    bool
+	Tooltip   string
+	Staleness core.Staleness
+	Actions   [][]PRAction
+	// Key is the ⌘ key equivalent opening the PR from the status menu, if any.
+	Key string
+}
+
+var stalenessDots = map[core.Staleness]string{
+	core.StalenessFresh:   "🟢",
+	core.StalenessAging:   "🟡",
+	core.StalenessOverdue: "🔴",
+}
+
+// PRStateFunc tells the per-PR local state the menu depends on, like seen and snoozed.
+type PRStateFunc func(pr github.PullRequest) PRActionState
+
+// OfflineNotice tells the menu shows the PRs of the last successful fetch.
+func OfflineNotice(since time.Time) string {
+	if since.IsZero() {
+		return "Offline — no data fetched yet"
+	}
+	return fmt.Sprintf("Offline — showing data from %s", since.Format("15:04"))
+}
+
+// BuildMenuModel orders categories by their order then name, and groups the shown and hidden PRs of each category
+// along its group_by dimension, by repository unless configured otherwise. PRs are ordered by the sort keys of their
+// category, and the first nine open with ⌘1 to ⌘9, starting with categories counting in the badge. Categories with
+// failed queries get a warning entry for each of them.
+func BuildMenuModel(prs core.PRMenuModel, errorHistory []core.ErrorEntry, conf config.Configuration, prState PRStateFunc, now time.Time) MenuModel {
+	model := MenuModel{}
+	calendar := core.NewBusinessCalendar(conf)
+	model.Sections = buildSections(prs.Shown, conf.QueryGroups, prState, calendar, now)
+	assignQuickOpenKeys(model.Sections, conf.QueryGroups)
+	counts := core.CountBadge(prs.Shown, conf.QueryGroups, func(pr github.PullRequest) bool {
+		return prState(pr).Seen
+	})
+	model.PRCount, model.Unseen, model.Dot = counts.Total, counts.Unseen, counts.Dot
+	model.Overdue = countOverdue(model.Sections)
+	failed := prs.FailedCategories()
+	for i, section := range model.Sections {
+		for _, queryError := range failed[section.Title] {
+			model.Sections[i].Warnings = append(model.Sections[i].Warnings, MenuMessage{
+				Title:  fmt.Sprintf("⚠ %s: %s", queryError.Query, github.UserMessage(queryError)),
+				Detail: queryError.Error(),
+			})
+		}
+	}
+	model.Hidden = buildSections(restorablePRs(prs.Hidden, conf.RenderHiddenPRs, prState), conf.QueryGroups, prState, calendar, now)
+	model.HiddenCount = countDistinct(model.Hidden)
+	for _, entry := range errorHistory {
+		title := fmt.Sprintf("%s %s", entry.Time.Format("15:04:05"), github.UserMessage(entry.Err))
+		if entry.Category != "" {
+			title = fmt.Sprintf("%s (%s: %s)", title, entry.Category, entry.Query)
+		}
+		model.Errors = append(model.Errors, MenuMessage{Title: title, Detail: entry.Err.Error()})
+	}
+	model.Commands = []MenuCommand{
+		{Kind: CommandMarkAllSeen, Title: "Mark all as seen", Key: "m"},
+		{Kind: CommandRefresh, Title: "Refresh", Key: "r"},
+		{Kind: CommandOpenLogs, Title: "Open logs", Key: "l"},
+		{Kind: CommandQuit, Title: "Quit", Key: "q"},
+	}
+	model.Title = buildTitle(model, conf)
+	if len(prs.Errors) > 0 {
+		model.Title = model.Title + "❗"
+	}
+	return model
+}
+
+// restorablePRs keeps the hidden PRs the Hidden PRs submenu lists: all of them with render_hidden_prs, otherwise only
+// those hidden or snoozed from the menu, so they can always be unhidden or unsnoozed there.
+func restorablePRs(hidden map[string][]github.PullRequest, renderHidden bool, prState PRStateFunc) map[string][]github.PullRequest {
+	restorable := make(map[string][]github.PullRequest)
+	for category, prs := range hidden {
+		for _, pr := range prs {
+			state := prState(pr)
+			if renderHidden || state.HiddenPR || state.HiddenRepository || state.Snoozed {
+				restorable[category] = append(restorable[category], pr)
+			}
+		}
+	}
+	return restorable
+}
+
+func countDistinct(sections []MenuSection) int {
+	listed := make(map[string]bool)
+	for _, section := range sections {
+		for _, pr := range section.PRs() {
+			listed[fmt.Sprintf("%s#%d", pr.Repository, pr.Number)] = true
+		}
+	}
+	return len(listed)
+}
+
+// HiddenTitle is the title of the Hidden PRs submenu.
+func (m MenuModel) HiddenTitle() string {
+	return fmt.Sprintf("Hidden PRs (%d)", m.HiddenCount)
+}
+
+// buildTitle evaluates title_template when set, falling back to the unseen/total count on evaluation errors. A dot
+// follows the count when a contributing category with the dot badge_style has PRs.
+func buildTitle(model MenuModel, conf config.Configuration) string {
+	if conf.TitleIconOnly {
+		return ""
+	}
+	tmpl, err := conf.StatusTitleTemplate()
+	if err == nil && tmpl != nil {
+		var title strings.Builder
+		if err = tmpl.Execute(&title, buildTitleData(model, conf.QueryGroups)); err == nil {
+			return title.String()
+		}
+	}
+	if err != nil {
+		slog.Warn("Error evaluating title_template, using the default title", "error", err)
+	}
+	title := strconv.Itoa(model.PRCount)
+	if model.Unseen > 0 {
+		title = fmt.Sprintf("%d/%d", model.Unseen, model.PRCount)
+	}
+	if model.Dot && model.PRCount == 0 {
+		title = "●"
+	} else if model.Dot {
+		title = title + " ●"
+	}
+	if model.Overdue > 0 {
+		title = fmt.Sprintf("%s %s%d", title, stalenessDots[core.StalenessOverdue], model.Overdue)
+	}
+	return title
+}
+
+func buildSections(categoryPRs map[string][]github.PullRequest, groups map[string]config.QueryGroup, prState PRStateFunc, calendar core.BusinessCalendar, now time.Time) []MenuSection {
+	categories := make([]string, 0, len(categoryPRs))
+	for category := range categoryPRs {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		orderI, orderJ := groups[categories[i]].Order, groups[categories[j]].Order
+		return orderI < orderJ || orderI == orderJ && categories[i] < categories[j]
+	})
+	sections := make([]MenuSection, 0, len(categories))
+	for _, category := range categories {
+		group := groups[category]
+		sortKeys, _ := group.SortKeys()
+		prs := core.SortPRs(categoryPRs[category], sortKeys)
+		grouping, _ := group.Grouping()
+		badgeStyle, _ := group.Badge()
+		section := MenuSection{Title: category, Badge: core.CategoryBadge(len(prs), badgeStyle)}
+		format := newItemFormat(category, group)
+		for _, prGroup := range core.GroupPRs(prs, grouping, now) {
+			subsection := MenuSubsection{Title: prGroup.Title}
+			for _, pr := range prGroup.PRs {
+				item := buildPRItem(pr, prState(pr), format, grouping != config.GroupByRepository, now)
+				if group.SLA != nil {
+					item.Staleness = core.StalenessOf(pr, *group.SLA, calendar, now)
+				}
+				subsection.Items = append(subsection.Items, item)
+			}
+			section.Subsections = append(section.Subsections, subsection)
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+func buildPRItem(pr github.PullRequest, state PRActionState, format itemFormat, withRepository bool, now time.Time) MenuPRItem {
+	title := format.title(pr, withRepository, now)
+	if state.Snoozed {
+		title = fmt.Sprintf("%s (snoozed %s)", title, state.SnoozedFor)
+	}
+	item := MenuPRItem{
+		PR:      pr,
+		Title:   title,
+		Tooltip: itemTooltip(pr),
+		Actions: PRActions(pr, state),
+	}
+	if !state.Seen {
+		item.Unseen = true
+		item.Badge = "●"
+		item.Bold = true
+	}
+	return item
+}
+
+// assignQuickOpenKeys gives the items of the first PRs the keys 1 to 9, those of categories counting in the
+// badge first, then in menu order.
+func assignQuickOpenKeys(sections []MenuSection, groups map[string]config.QueryGroup) {
+	prioritized := make([]MenuSection, 0, len(sections))
+	for _, counting := range []bool{true, false} {
+		for _, section := range sections {
+			if groups[section.Title].CountsInBadge() == counting {
+				prioritized = append(prioritized, section)
+			}
+		}
+	}
+	assigned := make(map[string]bool)
+	next := 0
+	for _, section := range prioritized {
+		for _, subsection := range section.Subsections {
+			for i := range subsection.Items {
+				item := &subsection.Items[i]
+				key := fmt.Sprintf("%s#%d", item.PR.Repository, item.PR.Number)
+				if next >= len(quickOpenKeys) || assigned[key] {
+					continue
+				}
+				assigned[key] = true
+				item.Key = quickOpenKeys[next]
+				next++
+			}
+		}
+	}
+}
+
+func countOverdue(sections []MenuSection) int {
+	overdue := make(map[string]bool)
+	for _, section := range sections {
+		for _, subsection := range section.Subsections {
+			for _, item := range subsection.Items {
+				if item.Staleness == core.StalenessOverdue {
+					overdue[fmt.Sprintf("%s#%d", item.PR.Repository, item.PR.Number)] = true
+				}
+			}
+		}
+	}
+	return len(overdue)
+}
+
+// DisplayTitle is the category followed by its badge, if any.
+func (section MenuSection) DisplayTitle() string {
+	switch section.Badge {
+	case "":
+		return section.Title
+	case "●":
+		return section.Title + " ●"
+	}
+	return fmt.Sprintf("%s (%s)", section.Title, section.Badge)
+}
+
+// MatchesSearch tells whether the item stays visible while filtering the menu with query, matching title,
+// repository, author and number.
+func (item MenuPRItem) MatchesSearch(query string) bool {
+	return fuzzy.MatchAll(query, item.PR.Title, item.PR.Repository, item.PR.Author, "#"+strconv.Itoa(item.PR.Number))
+}
+
+// DisplayTitle is the item title prefixed by its staleness dot and badge, as shown in the menu.
+func (item MenuPRItem) DisplayTitle() string {
+	title := item.Title
+	if item.Badge != "" {
+		title = item.Badge + " " + title
+	}
+	if dot := stalenessDots[item.Staleness]; dot != "" {
+		title = dot + " " + title
+	}
+	return title
+}
+
+// Text renders the model as an indented tree, one line per menu entry.
+func (m MenuModel) Text() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "[%s]\n", m.Title)
+	if m.Notice != "" {
+		fmt.Fprintf(&builder, "%s\n", m.Notice)
+	}
+	writeSectionsText(&builder, m.Sections, "")
+	if m.HiddenCount > 0 {
+		fmt.Fprintf(&builder, "%s\n", m.HiddenTitle())
+		writeSectionsText(&builder, m.Hidden, "  ")
+	}
+	if len(m.Errors) > 0 {
+		builder.WriteString("Errors\n")
+		for _, entry := range m.Errors {
+			fmt.Fprintf(&builder, "  %s\n", entry.Title)
+		}
+	}
+	for _, command := range m.Commands {
+		fmt.Fprintf(&builder, "%s (%s)\n", command.Title, command.Key)
+	}
+	return builder.String()
+}
+
+func writeSectionsText(builder *strings.Builder, sections []MenuSection, indent string) {
+	for _, section := range sections {
+		fmt.Fprintf(builder, "%s%s\n", indent, section.DisplayTitle())
+		for _, warning := range section.Warnings {
+			fmt.Fprintf(builder, "%s  %s\n", indent, warning.Title)
+		}
+		for _, subsection := range section.Subsections {
+			itemIndent := indent + "  "
+			if subsection.Title != "" {
+				fmt.Fprintf(builder, "%s%s\n", itemIndent, subsection.Title)
+				itemIndent = itemIndent + "  "
+			}
+			for _, item := range subsection.Items {
+				fmt.Fprintf(builder, "%s%s\n", itemIndent, item.DisplayTitle())
+			}
+		}
+	}
+}
@@ -0,0 +1,713 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+	"macos-gh-bar/config"
+	"macos-gh-bar/core"
+	"macos-gh-bar/github"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC)
+
+func testPR(repository string, number int, title string, author string) github.PullRequest {
+	return github.PullRequest{
+		Repository: repository,
+		Number:     number,
+		Title:      title,
+		Author:     author,
+		URL:        fmt.Sprintf("https://github.com/%s/pull/%d", repository, number),
+		CreatedAt:  testNow.Add(-time.Duration(number) * 24 * time.Hour),
+		UpdatedAt:  testNow.Add(-time.Duration(number) * time.Hour),
+	}
+}
+
+// stateOf marks the PRs of seen as seen and those of snoozed as snoozed until next push.
+func stateOf(seen []int, snoozed []int) PRStateFunc {
+	return func(pr github.PullRequest) PRActionState {
+		state := PRActionState{}
+		for _, number := range seen {
+			state.Seen = state.Seen || pr.Number == number
+		}
+		for _, number := range snoozed {
+			if pr.Number == number {
+				state.Snoozed, state.SnoozedFor = true, "until next push"
+			}
+		}
+		return state
+	}
+}
+
+// menuTextTest is a golden test of the menu model rendered as text.
+type menuTextTest struct {
+	name         string
+	prs          core.PRMenuModel
+	errorHistory []core.ErrorEntry
+	config       config.Configuration
+	prState      PRStateFunc
+	want         string
+}
+
+func runMenuTextTests(t *testing.T, tests []menuTextTest) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := BuildMenuModel(test.prs, test.errorHistory, test.config, test.prState, testNow).Text()
+			if got != test.want {
+				t.Errorf("Text() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+// The PRs of the golden tests, api1 and web3 carry labels.
+var (
+	api1 = labeled(testPR("acme/api", 1, "Fix login", "alice"), "bug", "security")
+	api2 = testPR("acme/api", 2, "Add metrics", "bob")
+	web3 = labeled(testPR("acme/web", 3, "Dark mode", "alice"), "ui")
+	web4 = testPR("acme/web", 4, "Bump deps", "dependabot")
+)
+
+func labeled(pr github.PullRequest, labels ...string) github.PullRequest {
+	pr.Labels = labels
+	return pr
+}
+
+func TestMenuModelText(t *testing.T) {
+	runMenuTextTests(t, []menuTextTest{
+		{
+			name: "grouped by repository with unseen items",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1, web3, api2},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {Sort: []string{"number"}},
+			}},
+			prState: stateOf([]int{2}, nil),
+			want: `[2/3]
+To Review (3)
+  acme/api
+    ● Fix login [#1]
+    Add metrics [#2]
+  acme/web
+    ● Dark mode [#3]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "hidden and snoozed PRs",
+			prs: core.PRMenuModel{
+				Shown:  map[string][]github.PullRequest{"To Review": {api1}},
+				Hidden: map[string][]github.PullRequest{"To Review": {web3, web4}},
+			},
+			config: config.Configuration{RenderHiddenPRs: true, QueryGroups: map[string]config.QueryGroup{
+				"To Review": {BadgeStyle: config.BadgeStyleNone, Sort: []string{"number"}},
+			}},
+			prState: stateOf([]int{1, 3, 4}, []int{3}),
+			want: `[0]
+To Review
+  acme/api
+    Fix login [#1]
+Hidden PRs (2)
+  To Review
+    acme/web
+      Dark mode [#3] (snoozed until next push)
+      Bump deps [#4]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "snoozed PRs listed without render_hidden_prs",
+			prs: core.PRMenuModel{
+				Shown:  map[string][]github.PullRequest{"To Review": {api1}},
+				Hidden: map[string][]github.PullRequest{"To Review": {web3, web4}, "Created": {web4}},
+			},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {BadgeStyle: config.BadgeStyleNone, Sort: []string{"number"}},
+			}},
+			prState: stateOf([]int{1, 3, 4}, []int{3}),
+			want: `[0]
+To Review
+  acme/api
+    Fix login [#1]
+Hidden PRs (1)
+  To Review
+    acme/web
+      Dark mode [#3] (snoozed until next push)
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "no hidden PRs submenu when nothing is restorable",
+			prs: core.PRMenuModel{
+				Shown:  map[string][]github.PullRequest{"To Review": {api1}},
+				Hidden: map[string][]github.PullRequest{"To Review": {web4}},
+			},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {BadgeStyle: config.BadgeStyleNone},
+			}},
+			prState: stateOf([]int{1, 4}, nil),
+			want: `[0]
+To Review
+  acme/api
+    Fix login [#1]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+	})
+}
+
+func TestMenuModelErrors(t *testing.T) {
+	rateLimited := core.QueryError{Category: "Created", Query: "is:pr author:@me", Err: &github.RateLimitError{Query: "is:pr author:@me", Status: 403}}
+	notFound := core.QueryError{Category: "Team", Query: "repo:acme/gone", Err: errors.New("404 Not Found")}
+
+	runMenuTextTests(t, []menuTextTest{
+		{
+			name: "failed query of the last fetch",
+			prs: core.PRMenuModel{
+				Shown: map[string][]github.PullRequest{
+					"To Review": {api1},
+					"Created":   {web3},
+				},
+				Errors: []core.QueryError{rateLimited},
+			},
+			errorHistory: []core.ErrorEntry{
+				{Time: testNow.Add(-time.Minute), Category: "Created", Query: "is:pr author:@me", Err: rateLimited},
+			},
+			config:  config.Configuration{},
+			prState: stateOf([]int{1, 3}, nil),
+			want: `[2❗]
+Created (1)
+  ⚠ is:pr author:@me: GitHub rate limit exceeded
+  acme/web
+    Dark mode [#3]
+To Review (1)
+  acme/api
+    Fix login [#1]
+Errors
+  11:59:00 GitHub rate limit exceeded (Created: is:pr author:@me)
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "errors of earlier fetches",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1},
+			}},
+			errorHistory: []core.ErrorEntry{
+				{Time: testNow.Add(-time.Minute), Category: "Team", Query: "repo:acme/gone", Err: notFound},
+				{Time: testNow.Add(-time.Hour), Err: errors.New("network is unreachable")},
+			},
+			config:  config.Configuration{},
+			prState: stateOf([]int{1}, nil),
+			want: `[1]
+To Review (1)
+  acme/api
+    Fix login [#1]
+Errors
+  11:59:00 404 Not Found (Team: repo:acme/gone)
+  11:00:00 network is unreachable
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+	})
+}
+
+func TestMenuModelStaleness(t *testing.T) {
+	everyDay := []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+	runMenuTextTests(t, []menuTextTest{
+		{
+			name: "aging and overdue PRs",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1, api2, web3},
+				"Created":   {web4},
+			}},
+			config: config.Configuration{
+				WorkingDays: everyDay,
+				QueryGroups: map[string]config.QueryGroup{
+					"To Review": {Sort: []string{"number"}, SLA: &config.SLA{AgingAfterHours: 1, OverdueAfterHours: 3}},
+					"Created":   {},
+				},
+			},
+			prState: stateOf([]int{1, 2, 3, 4}, nil),
+			want: `[4 🔴1]
+Created (1)
+  acme/web
+    Bump deps [#4]
+To Review (3)
+  acme/api
+    🟡 Fix login [#1]
+    🟡 Add metrics [#2]
+  acme/web
+    🔴 Dark mode [#3]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "holidays not counted",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api2},
+			}},
+			config: config.Configuration{
+				Holidays: []string{"2026-03-04"},
+				QueryGroups: map[string]config.QueryGroup{
+					"To Review": {SLA: &config.SLA{AgingAfterHours: 1, OverdueAfterHours: 3}},
+				},
+			},
+			prState: stateOf([]int{2}, nil),
+			want: `[1]
+To Review (1)
+  acme/api
+    🟢 Add metrics [#2]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+	})
+}
+
+func TestMenuModelBadges(t *testing.T) {
+	notCounted := false
+	runMenuTextTests(t, []menuTextTest{
+		{
+			name: "dot badge and an uncounted category",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1, web3, web4},
+				"Created":   {api2},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {BadgeStyle: config.BadgeStyleDot, Sort: []string{"number"}},
+				"Created":   {CountInBadge: &notCounted},
+			}},
+			prState: stateOf([]int{1, 2, 3, 4}, nil),
+			want: `[●]
+Created (1)
+  acme/api
+    Add metrics [#2]
+To Review ●
+  acme/api
+    Fix login [#1]
+  acme/web
+    Dark mode [#3]
+    Bump deps [#4]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "PRs of several categories counted once",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1, web3},
+				"Assigned":  {web3, web4},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {Sort: []string{"number"}},
+				"Assigned":  {Sort: []string{"number"}},
+			}},
+			prState: stateOf([]int{1}, nil),
+			want: `[2/3]
+Assigned (2)
+  acme/web
+    ● Dark mode [#3]
+    ● Bump deps [#4]
+To Review (2)
+  acme/api
+    Fix login [#1]
+  acme/web
+    ● Dark mode [#3]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "category without badge not counted",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1, web3},
+				"Assigned":  {web4},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {BadgeStyle: config.BadgeStyleNone, Sort: []string{"number"}},
+			}},
+			prState: stateOf([]int{1}, nil),
+			want: `[1/1]
+Assigned (1)
+  acme/web
+    ● Bump deps [#4]
+To Review
+  acme/api
+    Fix login [#1]
+  acme/web
+    ● Dark mode [#3]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "no category counted",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"Created": {api2},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"Created": {CountInBadge: &notCounted},
+			}},
+			prState: stateOf(nil, nil),
+			want: `[0]
+Created (1)
+  acme/api
+    ● Add metrics [#2]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+	})
+}
+
+func TestMenuModelItemFormat(t *testing.T) {
+	draft := api2
+	draft.Draft, draft.CIState, draft.ReviewState = true, github.CIStateFailure, github.ReviewStateApproved
+
+	runMenuTextTests(t, []menuTextTest{
+		{
+			name: "item format and width",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1, web4},
+				"Broken":    {api2},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {ItemFormat: "{{.RepoName}}#{{.Number}} {{.Title}} by {{.Author}}, {{.Age}}", ItemMaxWidth: 30, Sort: []string{"number"}},
+				"Broken":    {ItemFormat: "{{.Missing"},
+			}},
+			prState: stateOf([]int{1, 2, 4}, nil),
+			want: `[3]
+Broken (1)
+  acme/api
+    Add metrics [#2]
+To Review (2)
+  acme/api
+    api#1 Fix login by alice, 1d
+  acme/web
+    web#4 Bump deps by dependabot…
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "labels, draft and status glyphs",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1, draft},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {
+					ItemFormat: "{{if .Draft}}[draft] {{end}}{{.CIGlyph}}{{.ReviewGlyph}} {{.Title}} ({{.Labels}})",
+					Sort:       []string{"number"},
+				},
+			}},
+			prState: stateOf([]int{1, 2}, nil),
+			want: `[2]
+To Review (2)
+  acme/api
+    Fix login (bug, security)
+    [draft] ❌👍 Add metrics ()
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "evaluation errors fall back to the default format",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {ItemFormat: "{{.Missing}}"},
+			}},
+			prState: stateOf([]int{1}, nil),
+			want: `[1]
+To Review (1)
+  acme/api
+    Fix login [#1]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+	})
+}
+
+func TestMenuModelGrouping(t *testing.T) {
+	approved := web3
+	approved.ReviewState = github.ReviewStateApproved
+	changesRequested := web4
+	changesRequested.ReviewState = github.ReviewStateChangesRequested
+	today := testPR("acme/web", 5, "Fresh", "carol")
+	today.CreatedAt = testNow.Add(-time.Hour)
+	old := testPR("acme/api", 10, "Stale", "carol")
+
+	runMenuTextTests(t, []menuTextTest{
+		{
+			name: "grouped by author",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1, web3, web4},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {GroupBy: config.GroupByAuthor, Sort: []string{"number"}},
+			}},
+			prState: stateOf([]int{1, 3, 4}, nil),
+			want: `[3]
+To Review (3)
+  alice
+    Fix login [acme/api#1]
+    Dark mode [acme/web#3]
+  dependabot
+    Bump deps [acme/web#4]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "grouped by label",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1, web3, web4},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {GroupBy: config.GroupByLabel, Sort: []string{"number"}},
+			}},
+			prState: stateOf([]int{1, 3, 4}, nil),
+			want: `[3]
+To Review (3)
+  No label
+    Bump deps [acme/web#4]
+  bug
+    Fix login [acme/api#1]
+  security
+    Fix login [acme/api#1]
+  ui
+    Dark mode [acme/web#3]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "grouped by review decision",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {api1, approved, changesRequested},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {GroupBy: config.GroupByReviewDecision, Sort: []string{"number"}},
+			}},
+			prState: stateOf([]int{1, 3, 4}, nil),
+			want: `[3]
+To Review (3)
+  Changes requested
+    Bump deps [acme/web#4]
+  Approved
+    Dark mode [acme/web#3]
+  Unknown review state
+    Fix login [acme/api#1]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "grouped by age",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {old, api1, today},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {GroupBy: config.GroupByAge, Sort: []string{"number"}},
+			}},
+			prState: stateOf([]int{1, 5, 10}, nil),
+			want: `[3]
+To Review (3)
+  Today
+    Fresh [acme/web#5]
+  This week
+    Fix login [acme/api#1]
+  Older
+    Stale [acme/api#10]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+		{
+			name: "ungrouped",
+			prs: core.PRMenuModel{Shown: map[string][]github.PullRequest{
+				"To Review": {web4, api1},
+			}},
+			config: config.Configuration{QueryGroups: map[string]config.QueryGroup{
+				"To Review": {GroupBy: config.GroupByNone, Sort: []string{"number"}},
+			}},
+			prState: stateOf([]int{1, 4}, nil),
+			want: `[2]
+To Review (2)
+  Fix login [acme/api#1]
+  Bump deps [acme/web#4]
+Mark all as seen (m)
+Refresh (r)
+Open logs (l)
+Quit (q)
+`,
+		},
+	})
+}
+
+func TestQuickOpenKeys(t *testing.T) {
+	notCounted := false
+	created := make([]github.PullRequest, 0)
+	for number := 1; number <= 3; number++ {
+		created = append(created, testPR("acme/api", number, "Mine", "me"))
+	}
+	toReview := make([]github.PullRequest, 0)
+	for number := 10; number <= 17; number++ {
+		toReview = append(toReview, testPR("acme/web", number, "Theirs", "alice"))
+	}
+	// PR 1 is listed in both categories, only its first listing gets a key
+	toReview = append(toReview, created[0])
+	prs := core.PRMenuModel{Shown: map[string][]github.PullRequest{"Created": created, "To Review": toReview}}
+	conf := config.Configuration{QueryGroups: map[string]config.QueryGroup{
+		"Created":   {CountInBadge: &notCounted, Sort: []string{"number"}},
+		"To Review": {Sort: []string{"number"}, GroupBy: config.GroupByNone},
+	}}
+
+	model := BuildMenuModel(prs, nil, conf, stateOf(nil, nil), testNow)
+	keys := make(map[string]string)
+	for _, section := range model.Sections {
+		for _, item := range section.Subsections[0].Items {
+			if item.Key != "" {
+				keys[fmt.Sprintf("%s %s#%d", section.Title, item.PR.Repository, item.PR.Number)] = item.Key
+			}
+		}
+	}
+	want := map[string]string{
+		"To Review acme/api#1":  "1",
+		"To Review acme/web#10": "2",
+		"To Review acme/web#11": "3",
+		"To Review acme/web#12": "4",
+		"To Review acme/web#13": "5",
+		"To Review acme/web#14": "6",
+		"To Review acme/web#15": "7",
+		"To Review acme/web#16": "8",
+		"To Review acme/web#17": "9",
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("quick open keys = %v, want %v", keys, want)
+	}
+}
+
+func TestTitleTemplate(t *testing.T) {
+	// every field title_template can use is accepted when the configuration loads
+	fields := reflect.TypeOf(TitleData{})
+	for i := 0; i < fields.NumField(); i++ {
+		if field := fields.Field(i); field.IsExported() {
+			conf := config.Configuration{TitleTemplate: "{{." + field.Name + "}}"}
+			if _, err := conf.StatusTitleTemplate(); err != nil {
+				t.Errorf("StatusTitleTemplate() rejects field %s: %v", field.Name, err)
+			}
+		}
+	}
+
+	prs := core.PRMenuModel{Shown: map[string][]github.PullRequest{
+		"To Review": {testPR("acme/api", 1, "Fix login", "alice"), testPR("acme/api", 2, "Add metrics", "bob")},
+	}}
+	tests := []struct {
+		name      string
+		template  string
+		want      string
+		wantError string
+	}{
+		{name: "counts", template: `{{.Unseen}}/{{.Total}} {{.Category "To Review"}} {{.Category "Unknown"}}`, want: "1/2 2 0"},
+		{name: "unknown field", template: "{{.Missing}}", want: "1/2", wantError: "can't evaluate field Missing"},
+		{name: "wrong method arguments", template: "{{.Category}}", want: "1/2", wantError: "wrong number of args"},
+		{name: "parse error", template: "{{.Total", want: "1/2", wantError: "unclosed action"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := config.Configuration{TitleTemplate: test.template}
+			_, err := conf.StatusTitleTemplate()
+			if test.wantError == "" && err != nil || test.wantError != "" && (err == nil || !strings.Contains(err.Error(), test.wantError)) {
+				t.Errorf("StatusTitleTemplate() error = %v, want %q", err, test.wantError)
+			}
+			if got := BuildMenuModel(prs, nil, conf, stateOf([]int{2}, nil), testNow).Title; got != test.want {
+				t.Errorf("Title = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchingPRs(t *testing.T) {
+	api1 := testPR("acme/api", 1, "Fix login", "alice")
+	api1.Labels = []string{"bug", "security"}
+	web2 := testPR("acme/web", 2, "Login page", "bob")
+	web3 := testPR("acme/web", 3, "Dark mode", "alice")
+	prs := core.PRMenuModel{Shown: map[string][]github.PullRequest{"To Review": {api1, web2, web3}}}
+	conf := config.Configuration{QueryGroups: map[string]config.QueryGroup{
+		"To Review": {GroupBy: config.GroupByLabel, Sort: []string{"number"}},
+	}}
+	section := BuildMenuModel(prs, nil, conf, stateOf(nil, nil), testNow).Sections[0]
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "", want: []string{"acme/web#2", "acme/web#3", "acme/api#1"}},
+		{query: "login", want: []string{"acme/web#2", "acme/api#1"}},
+		{query: "alice", want: []string{"acme/web#3", "acme/api#1"}},
+		{query: "nothing", want: []string{}},
+	}
+	for _, test := range tests {
+		got := make([]string, 0)
+		for _, pr := range section.MatchingPRs(test.query) {
+			got = append(got, fmt.Sprintf("%s#%d", pr.Repository, pr.Number))
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("MatchingPRs(%q) = %v, want %v", test.query, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+//go:build darwin
+
+package view
+
+import (
+	"fmt"
+	"log/slog"
+	"macos-gh-bar/github"
+	"strings"
+
+	"github.com/progrium/darwinkit/helper/action"
+	"github.com/progrium/darwinkit/macos/appkit"
+	"github.com/progrium/darwinkit/macos/foundation"
+	"github.com/progrium/darwinkit/objc"
+)
+
+type MenuHandlers struct {
+	RunPRAction func(pr github.PullRequest, action PRAction)
+	RunCommand  func(command MenuCommandKind)
+	OpenAll     func(prs []github.PullRequest)
+}
+
+// RenderStatusMenu replaces the status menu contents with the model, it must run on the main queue.
+func RenderStatusMenu(statusItem appkit.StatusItem, menu appkit.Menu, model MenuModel, handlers MenuHandlers) {
+	menu.RemoveAllItems()
+	if model.Notice != "" {
+		menu.AddItem(MenuItemDisabled(model.Notice))
+	}
+	filter := &menuFilter{}
+	menu.AddItem(searchMenuItem(filter))
+	renderSections(menu, model.Sections, handlers, filter)
+	menu.AddItem(MenuSeparator())
+	menu.AddItem(MenuSeparator())
+
+	if model.HiddenCount > 0 {
+		hiddenPRsItem := MenuItemNoAction(model.HiddenTitle(), "x")
+		hiddenItemsMenu := NewMenuWithTitle("Hidden PRs")
+		renderSections(hiddenItemsMenu, model.Hidden, handlers, filter)
+		hiddenPRsItem.SetSubmenu(hiddenItemsMenu)
+		menu.AddItem(hiddenPRsItem)
+	}
+
+	if len(model.Errors) > 0 {
+		errorsItem := MenuItemNoAction("Errors", "e")
+		errorsMenu := NewMenuWithTitle("Errors")
+		for _, entry := range model.Errors {
+			errorsMenu.AddItem(messageMenuItem(entry))
+		}
+		errorsItem.SetSubmenu(errorsMenu)
+		menu.AddItem(errorsItem)
+	}
+
+	for _, command := range model.Commands {
+		menu.AddItem(MenuItem(command.Title, command.Key, func(sender objc.Object) {
+			handlers.RunCommand(command.Kind)
+		}))
+	}
+	statusItem.Button().SetTitle(model.Title)
+}
+
+func renderSections(menu appkit.Menu, sections []MenuSection, handlers MenuHandlers, filter *menuFilter) {
+	for _, section := range sections {
+		filteredSection := filteredSection{}
+		addItem := func(item appkit.MenuItem) {
+			filteredSection.chrome = append(filteredSection.chrome, item)
+			menu.AddItem(item)
+		}
+		addItem(MenuSeparator())
+		addItem(MenuItemSectionLabel(section.DisplayTitle()))
+		addItem(MenuSeparator())
+		slog.Debug("Rendering category", "category", section.Title, "count", section.Badge)
+		for _, warning := range section.Warnings {
+			addItem(messageMenuItem(warning))
+		}
+		if len(section.PRs()) > 1 {
+			openAll := MenuItem(fmt.Sprintf("Open all in %s", section.Title), "", func(sender objc.Object) {
+				handlers.OpenAll(section.MatchingPRs(filter.query))
+			})
+			filteredSection.openAll = &openAll
+			menu.AddItem(openAll)
+		}
+		for _, subsection := range section.Subsections {
+			slog.Debug("Rendering repository", "repository", subsection.Title, "count", len(subsection.Items))
+			filtered := filteredSubsection{}
+			if subsection.Title != "" {
+				label := MenuItemSubsectionLabel(subsection.Title)
+				filtered.label = &label
+				menu.AddItem(label)
+			}
+			for _, item := range subsection.Items {
+				slog.Debug("Rendering PR", "repository", item.PR.Repository, "number", item.PR.Number)
+				menuItem := prMenuItem(item, handlers)
+				filtered.items = append(filtered.items, filteredItem{menuItem: menuItem, item: item})
+				menu.AddItem(menuItem)
+			}
+			filteredSection.subsections = append(filteredSection.subsections, filtered)
+		}
+		addItem(MenuSeparator())
+		filteredSection.section = section
+		filter.sections = append(filter.sections, filteredSection)
+	}
+}
+
+// prMenuItem lists the actions of the PR in a submenu, its quick open key opens the PR without entering it.
+func prMenuItem(item MenuPRItem, handlers MenuHandlers) appkit.MenuItem {
+	menuItem := MenuItemNoAction(item.Title, "")
+	if item.Key != "" {
+		menuItem = MenuItem(item.Title, item.Key, func(sender objc.Object) {
+			handlers.RunPRAction(item.PR, PRAction{Kind: ActionOpen, Title: "Open PR", URL: item.PR.URL})
+		})
+	}
+	if item.Bold || item.DisplayTitle() != item.Title {
+		SetMenuItemTitle(menuItem, item.DisplayTitle(), item.Bold)
+	}
+	menuItem.SetToolTip(item.Tooltip)
+	submenu := NewMenuWithTitle(item.Title)
+	for i, group := range item.Actions {
+		if i > 0 {
+			submenu.AddItem(MenuSeparator())
+		}
+		for _, action := range group {
+			submenu.AddItem(MenuItem(action.Title, "", func(sender objc.Object) {
+				handlers.RunPRAction(item.PR, action)
+			}))
+		}
+	}
+	menuItem.SetSubmenu(submenu)
+	return menuItem
+}
+
+func messageMenuItem(message MenuMessage) appkit.MenuItem {
+	item := MenuItem(message.Title, "", func(sender objc.Object) {
+		DispatchAlert("GithubBar Error", message.Detail)
+	})
+	item.SetToolTip(message.Detail)
+	return item
+}
+
+// menuFilter hides the PR items not matching the search field, the subsection labels left without items and the
+// categories left without matches. Open all opens the matching PRs of its category only.
+type menuFilter struct {
+	query    string
+	sections []filteredSection
+}
+
+type filteredSection struct {
+	section MenuSection
+	// chrome holds the label, separators and warnings of the category, hidden with its last match.
+	chrome      []appkit.MenuItem
+	openAll     *appkit.MenuItem
+	subsections []filteredSubsection
+}
+
+type filteredSubsection struct {
+	label *appkit.MenuItem
+	items []filteredItem
+}
+
+type filteredItem struct {
+	menuItem appkit.MenuItem
+	item     MenuPRItem
+}
+
+func (f *menuFilter) apply(query string) {
+	f.query = query
+	for _, section := range f.sections {
+		sectionVisible := 0
+		for _, subsection := range section.subsections {
+			visible := 0
+			for _, filtered := range subsection.items {
+				matches := filtered.item.MatchesSearch(query)
+				filtered.menuItem.SetHidden(!matches)
+				if matches {
+					visible++
+				}
+			}
+			if subsection.label != nil {
+				subsection.label.SetHidden(visible == 0)
+			}
+			sectionVisible += visible
+		}
+		// categories stay listed while not filtering, even without PRs
+		hideSection := strings.TrimSpace(query) != "" && sectionVisible == 0
+		for _, item := range section.chrome {
+			item.SetHidden(hideSection)
+		}
+		if section.openAll != nil {
+			section.openAll.SetHidden(len(section.section.MatchingPRs(query)) < 2)
+		}
+	}
+}
+
+func searchMenuItem(filter *menuFilter) appkit.MenuItem {
+	searchField := appkit.NewSearchFieldWithFrame(foundation.Rect{Size: foundation.Size{Width: 260, Height: 24}})
+	searchField.SetPlaceholderString("Filter PRs")
+	searchField.SetSendsSearchStringImmediately(true)
+	action.Set(searchField, func(sender objc.Object) {
+		filter.apply(searchField.StringValue())
+	})
+	objc.Retain(&searchField)
+	item := appkit.NewMenuItem()
+	item.SetView(searchField)
+	objc.Retain(&item)
+	return item
+}
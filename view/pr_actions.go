@@ -0,0 +1,86 @@
+package view
+
+import (
+	"fmt"
+	"macos-gh-bar/github"
+)
+
+type PRActionKind string
+
+const (
+	ActionOpen             PRActionKind = "open"
+	ActionOpenFiles        PRActionKind = "open_files"
+	ActionOpenChecks       PRActionKind = "open_checks"
+	ActionCopyURL          PRActionKind = "copy_url"
+	ActionCopyCheckout     PRActionKind = "copy_checkout"
+	ActionCopyBranch       PRActionKind = "copy_branch"
+	ActionMarkSeen         PRActionKind = "mark_seen"
+	ActionHidePR           PRActionKind = "hide_pr"
+	ActionUnhidePR         PRActionKind = "unhide_pr"
+	ActionHideRepository   PRActionKind = "hide_repository"
+	ActionUnhideRepository PRActionKind = "unhide_repository"
+	ActionSnoozeHour       PRActionKind = "snooze_hour"
+	ActionSnoozeTomorrow   PRActionKind = "snooze_tomorrow"
+	ActionSnoozeUntilPush  PRActionKind = "snooze_until_push"
+	ActionUnsnooze         PRActionKind = "unsnooze"
+)
+
+// PRAction is one entry of a PR submenu. Open actions carry the URL to open and copy actions the text to copy, except
+// for the branch name of PRs found by search, which is empty until fetched.
+type PRAction struct {
+	Kind  PRActionKind
+	Title string
+	URL   string
+	Text  string
+}
+
+type PRActionState struct {
+	Seen             bool
+	Snoozed          bool
+	SnoozedFor       string
+	HiddenPR         bool
+	HiddenRepository bool
+}
+
+// PRActions lists the submenu actions of a PR in groups, to be rendered with separators between them.
+func PRActions(pr github.PullRequest, state PRActionState) [][]PRAction {
+	open := []PRAction{
+		{Kind: ActionOpen, Title: "Open PR", URL: pr.URL},
+		{Kind: ActionOpenFiles, Title: "Open Files changed", URL: pr.URL + "/files"},
+		{Kind: ActionOpenChecks, Title: "Open Checks", URL: pr.URL + "/checks"},
+	}
+	copyActions := []PRAction{
+		{Kind: ActionCopyURL, Title: "Copy URL", Text: pr.URL},
+		{Kind: ActionCopyCheckout, Title: "Copy checkout command", Text: fmt.Sprintf("gh pr checkout %d --repo %s", pr.Number, pr.Repository)},
+		{Kind: ActionCopyBranch, Title: "Copy branch name", Text: pr.HeadBranch},
+	}
+
+	quiet := make([]PRAction, 0)
+	if !state.Seen {
+		quiet = append(quiet, PRAction{Kind: ActionMarkSeen, Title: "Mark as seen"})
+	}
+	if state.HiddenPR {
+		quiet = append(quiet, PRAction{Kind: ActionUnhidePR, Title: "Unhide this PR"})
+	} else {
+		quiet = append(quiet, PRAction{Kind: ActionHidePR, Title: "Hide this PR"})
+	}
+	if state.HiddenRepository {
+		quiet = append(quiet, PRAction{Kind: ActionUnhideRepository, Title: fmt.Sprintf("Unhide %s", pr.Repository)})
+	} else {
+		quiet = append(quiet, PRAction{Kind: ActionHideRepository, Title: fmt.Sprintf("Hide %s", pr.Repository)})
+	}
+
+	snooze := make([]PRAction, 0)
+	if state.Snoozed {
+		snooze = append(snooze, PRAction{Kind: ActionUnsnooze, Title: "Unsnooze"})
+	} else {
+		snooze = append(snooze,
+			PRAction{Kind: ActionSnoozeHour, Title: "Snooze 1h"},
+			PRAction{Kind: ActionSnoozeTomorrow, Title: "Snooze until tomorrow"},
+		)
+		if pr.HeadSHA != "" {
+			snooze = append(snooze, PRAction{Kind: ActionSnoozeUntilPush, Title: "Snooze until next push"})
+		}
+	}
+	return [][]PRAction{open, copyActions, quiet, snooze}
+}
@@ -0,0 +1,112 @@
+package view
+
+import (
+	"macos-gh-bar/github"
+	"reflect"
+	"testing"
+)
+
+func actionKinds(groups [][]PRAction) [][]PRActionKind {
+	kinds := make([][]PRActionKind, 0, len(groups))
+	for _, group := range groups {
+		groupKinds := make([]PRActionKind, 0, len(group))
+		for _, action := range group {
+			groupKinds = append(groupKinds, action.Kind)
+		}
+		kinds = append(kinds, groupKinds)
+	}
+	return kinds
+}
+
+func TestPRActions(t *testing.T) {
+	pr := github.PullRequest{Repository: "acme/api", Number: 7, URL: "https://github.com/acme/api/pull/7", HeadSHA: "abc", HeadBranch: "fix-login"}
+	open := []PRActionKind{ActionOpen, ActionOpenFiles, ActionOpenChecks}
+	copyKinds := []PRActionKind{ActionCopyURL, ActionCopyCheckout, ActionCopyBranch}
+	snooze := []PRActionKind{ActionSnoozeHour, ActionSnoozeTomorrow, ActionSnoozeUntilPush}
+
+	tests := []struct {
+		name  string
+		pr    github.PullRequest
+		state PRActionState
+		want  [][]PRActionKind
+	}{
+		{
+			name: "unseen",
+			pr:   pr,
+			want: [][]PRActionKind{open, copyKinds, {ActionMarkSeen, ActionHidePR, ActionHideRepository}, snooze},
+		},
+		{
+			name:  "seen",
+			pr:    pr,
+			state: PRActionState{Seen: true},
+			want:  [][]PRActionKind{open, copyKinds, {ActionHidePR, ActionHideRepository}, snooze},
+		},
+		{
+			name:  "hidden PR",
+			pr:    pr,
+			state: PRActionState{Seen: true, HiddenPR: true},
+			want:  [][]PRActionKind{open, copyKinds, {ActionUnhidePR, ActionHideRepository}, snooze},
+		},
+		{
+			name:  "hidden repository",
+			pr:    pr,
+			state: PRActionState{Seen: true, HiddenRepository: true},
+			want:  [][]PRActionKind{open, copyKinds, {ActionHidePR, ActionUnhideRepository}, snooze},
+		},
+		{
+			name:  "snoozed",
+			pr:    pr,
+			state: PRActionState{Seen: true, Snoozed: true, SnoozedFor: "until next push"},
+			want:  [][]PRActionKind{open, copyKinds, {ActionHidePR, ActionHideRepository}, {ActionUnsnooze}},
+		},
+		{
+			name:  "head commit unknown",
+			pr:    github.PullRequest{Repository: "acme/api", Number: 7, URL: "https://github.com/acme/api/pull/7"},
+			state: PRActionState{Seen: true},
+			want:  [][]PRActionKind{open, copyKinds, {ActionHidePR, ActionHideRepository}, {ActionSnoozeHour, ActionSnoozeTomorrow}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := actionKinds(PRActions(test.pr, test.state)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("PRActions() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPRActionsText(t *testing.T) {
+	pr := github.PullRequest{Repository: "acme/api", Number: 7, URL: "https://github.com/acme/api/pull/7", HeadBranch: "fix-login"}
+	want := map[PRActionKind]string{
+		ActionOpen:             "https://github.com/acme/api/pull/7",
+		ActionOpenFiles:        "https://github.com/acme/api/pull/7/files",
+		ActionOpenChecks:       "https://github.com/acme/api/pull/7/checks",
+		ActionCopyURL:          "https://github.com/acme/api/pull/7",
+		ActionCopyCheckout:     "gh pr checkout 7 --repo acme/api",
+		ActionCopyBranch:       "fix-login",
+		ActionHideRepository:   "Hide acme/api",
+		ActionUnhideRepository: "Unhide acme/api",
+	}
+	for _, group := range append(PRActions(pr, PRActionState{}), PRActions(pr, PRActionState{HiddenRepository: true})...) {
+		for _, action := range group {
+			expected, found := want[action.Kind]
+			if !found {
+				continue
+			}
+			got := action.URL + action.Text
+			if action.Kind == ActionHideRepository || action.Kind == ActionUnhideRepository {
+				got = action.Title
+			}
+			if got != expected {
+				t.Errorf("%s = %q, want %q", action.Kind, got, expected)
+			}
+		}
+	}
+
+	pr.HeadBranch = ""
+	for _, action := range PRActions(pr, PRActionState{})[1] {
+		if action.Kind == ActionCopyBranch && action.Text != "" {
+			t.Errorf("copy branch of a search result = %q, want empty until fetched", action.Text)
+		}
+	}
+}
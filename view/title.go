@@ -0,0 +1,72 @@
+package view
+
+import (
+	"fmt"
+	"macos-gh-bar/config"
+	"macos-gh-bar/github"
+	"strings"
+)
+
+// TitleData is what title_template is evaluated against, counts are of distinct shown PRs.
+type TitleData struct {
+	Total   int
+	Unseen  int
+	Overdue int
+	// ToReview counts the PRs of categories searching review-requested:@me, Mine of those searching author:@me.
+	ToReview   int
+	Mine       int
+	FailingCI  int
+	categories map[string]int
+}
+
+// Category counts the shown PRs of a category, 0 for an unknown one.
+func (d TitleData) Category(name string) int {
+	return d.categories[name]
+}
+
+func buildTitleData(model MenuModel, groups map[string]config.QueryGroup) TitleData {
+	data := TitleData{
+		Total:      model.PRCount,
+		Unseen:     model.Unseen,
+		Overdue:    model.Overdue,
+		categories: make(map[string]int, len(model.Sections)),
+	}
+	toReview := make(map[string]bool)
+	mine := make(map[string]bool)
+	failing := make(map[string]bool)
+	for _, section := range model.Sections {
+		group := groups[section.Title]
+		reviewQuery := searchesFor(group, "review-requested:@me")
+		mineQuery := searchesFor(group, "author:@me")
+		listed := make(map[string]bool)
+		for _, subsection := range section.Subsections {
+			for _, item := range subsection.Items {
+				key := fmt.Sprintf("%s#%d", item.PR.Repository, item.PR.Number)
+				listed[key] = true
+				if reviewQuery {
+					toReview[key] = true
+				}
+				if mineQuery {
+					mine[key] = true
+				}
+				if item.PR.CIState == github.CIStateFailure {
+					failing[key] = true
+				}
+			}
+		}
+		data.categories[section.Title] = len(listed)
+	}
+	data.ToReview = len(toReview)
+	data.Mine = len(mine)
+	data.FailingCI = len(failing)
+	return data
+}
+
+func searchesFor(group config.QueryGroup, qualifier string) bool {
+	for _, query := range group.Queries {
+		if strings.Contains(query, qualifier) {
+			return true
+		}
+	}
+	return false
+}